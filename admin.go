@@ -0,0 +1,281 @@
+package clogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// settings is a snapshot of the logger's runtime settings, as changed by the admin handler and the signals.
+type settings struct {
+	level     Severity
+	verbosity int
+	vmodule   string
+	sampler   Sampler
+}
+
+func (l *DefaultLogger) settings() settings {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return settings{
+		level:     l.Level(),
+		verbosity: l.Verbosity(),
+		vmodule:   l.VModule(),
+		sampler:   l.sampler,
+	}
+}
+
+// setSettings publishes the settings at once, the write path reading the level and the sampler together,
+// see admission. The vmodule spec must be valid. l.mu must be held.
+func (l *DefaultLogger) setSettings(s settings) {
+	patterns, _ := parseVModule(s.vmodule)
+
+	atomic.StoreInt32(&l.level, int32(s.level))
+	l.verbosity.set(s.verbosity, patterns)
+	l.sampler = s.sampler
+}
+
+// updateSettings changes the settings through fn, unless it fails, then logs the change, from source,
+// at Notice whatever the minimum severity. Changes are serialized, whether they come from the admin
+// handler, the signals or config reloads.
+func (l *DefaultLogger) updateSettings(source string, fn func(s settings) (settings, error)) error {
+	l.settingsMu.Lock()
+	defer l.settingsMu.Unlock()
+
+	old := l.settings()
+	s, err := fn(old)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.setSettings(s)
+	l.mu.Unlock()
+
+	entry := newLogEntry()
+	entry.severity = SeverityNotice
+	entry.message = "Logger settings changed"
+	entry.template = entry.message
+	entry.fields.add("source", source)
+	entry.fields.add("old", old.state())
+	entry.fields.add("new", s.state())
+	l.writeLogEntry(entry)
+
+	return nil
+}
+
+// adminState is the JSON representation of the settings.
+type adminState struct {
+	Level      Severity   `json:"level"`
+	Verbosity  int        `json:"verbosity"`
+	VModule    string     `json:"vmodule"`
+	SampleRate *float64   `json:"sample_rate"`
+	RevertAt   *time.Time `json:"revert_at,omitempty"`
+}
+
+func (s settings) state() adminState {
+	state := adminState{
+		Level:     s.level,
+		Verbosity: s.verbosity,
+		VModule:   s.vmodule,
+	}
+
+	if ts, ok := s.sampler.(*TraceSampler); ok {
+		rate := ts.Rate()
+		state.SampleRate = &rate
+	}
+
+	return state
+}
+
+// adminChange is the body of the requests changing the settings. Missing fields are left as they are.
+type adminChange struct {
+	Level      *Severity `json:"level"`
+	Verbosity  *int      `json:"verbosity"`
+	VModule    *string   `json:"vmodule"`
+	SampleRate *float64  `json:"sample_rate"`
+
+	// Reverts the change once elapsed, e.g. "15m".
+	TTL string `json:"ttl"`
+}
+
+type adminHandler struct {
+	l *DefaultLogger
+
+	// Guards the pending revert
+	mu       sync.Mutex
+	revert   *time.Timer
+	revertAt *time.Time
+}
+
+// NewAdminHandler returns an http.Handler, meant for an admin port, showing and changing the runtime settings
+// of the logger: its minimum severity, verbosity, per-package verbosity and sampling rate.
+//
+// GET returns them as JSON. PUT or POST changes them, e.g.
+//
+//	{"level": "DEBUG", "verbosity": 2, "vmodule": "internal/db/*=3", "sample_rate": 0.5, "ttl": "15m"}
+//
+// Missing fields are left as they are. Changes apply all at once or not at all, and they are logged.
+// With a ttl, the changed settings are restored once elapsed, unless changed again in the meantime,
+// through the handler or otherwise. A sample_rate replaces the sampler with a TraceSampler; it is
+// refused if the logger has a sampler of another kind.
+func NewAdminHandler(l *DefaultLogger) http.Handler {
+	return &adminHandler{l: l}
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var change adminChange
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			http.Error(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if err := h.change(change); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mu.Lock()
+	state := h.l.settings().state()
+	state.RevertAt = h.revertAt
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(state)
+}
+
+// change validates the whole change before applying it.
+func (h *adminHandler) change(c adminChange) error {
+	var ttl time.Duration
+	if c.TTL != "" {
+		d, err := time.ParseDuration(c.TTL)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid ttl %q", c.TTL)
+		}
+		ttl = d
+	}
+
+	if c.VModule != nil {
+		if _, err := parseVModule(*c.VModule); err != nil {
+			return err
+		}
+	}
+
+	if c.SampleRate != nil && (*c.SampleRate < 0 || *c.SampleRate > 1) {
+		return fmt.Errorf("invalid sample_rate %v, expecting a value between 0 and 1", *c.SampleRate)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var prev, next settings
+	err := h.l.updateSettings("admin", func(cur settings) (settings, error) {
+		if _, ok := cur.sampler.(*TraceSampler); c.SampleRate != nil && cur.sampler != nil && !ok {
+			return cur, fmt.Errorf("refusing to replace the %T sampler with a sample_rate", cur.sampler)
+		}
+
+		prev, next = cur, cur
+		if c.Level != nil {
+			next.level = *c.Level
+		}
+		if c.Verbosity != nil {
+			next.verbosity = *c.Verbosity
+		}
+		if c.VModule != nil {
+			next.vmodule = *c.VModule
+		}
+		if c.SampleRate != nil {
+			next.sampler = NewTraceSampler(*c.SampleRate)
+		}
+
+		return next, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// A new change cancels the pending revert, if any.
+	if h.revert != nil {
+		h.revert.Stop()
+		h.revert, h.revertAt = nil, nil
+	}
+
+	if ttl > 0 {
+		at := time.Now().Add(ttl)
+		h.revertAt = &at
+
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			// Changed again in the meantime
+			if h.revert != timer {
+				return
+			}
+
+			_ = h.l.updateSettings("admin ttl", func(cur settings) (settings, error) {
+				return revertSettings(c, cur, prev, next), nil
+			})
+			h.revert, h.revertAt = nil, nil
+		})
+		h.revert = timer
+	}
+
+	return nil
+}
+
+// revertSettings restores the settings changed by c from next back to prev, leaving the others, along with
+// those changed since, as they are in cur.
+func revertSettings(c adminChange, cur, prev, next settings) settings {
+	if c.Level != nil && cur.level == next.level {
+		cur.level = prev.level
+	}
+	if c.Verbosity != nil && cur.verbosity == next.verbosity {
+		cur.verbosity = prev.verbosity
+	}
+	if c.VModule != nil && cur.vmodule == next.vmodule {
+		cur.vmodule = prev.vmodule
+	}
+	if c.SampleRate != nil && cur.sampler == next.sampler {
+		cur.sampler = prev.sampler
+	}
+
+	return cur
+}
+
+// stepLevel returns the predefined level next to sev, one step more verbose if down, one step less otherwise.
+func stepLevel(sev Severity, down bool) Severity {
+	levels := []Severity{
+		SeverityTrace, SeverityDebug, SeverityInfo, SeverityNotice, SeverityWarn,
+		SeverityError, SeverityCritical, SeverityAlert, SeverityEmergency,
+	}
+
+	if down {
+		for i := len(levels) - 1; i >= 0; i-- {
+			if levels[i] < sev {
+				return levels[i]
+			}
+		}
+		return sev
+	}
+
+	for _, level := range levels {
+		if level > sev {
+			return level
+		}
+	}
+	return sev
+}
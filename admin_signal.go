@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package clogger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals steps the minimum severity of the logger on SIGUSR1, one predefined level more verbose,
+// and on SIGUSR2, one predefined level less verbose. Every change is logged. Call stop to stop handling them.
+func HandleSignals(l *DefaultLogger) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = l.updateSettings("signal "+sig.String(), func(s settings) (settings, error) {
+					s.level = stepLevel(s.level, sig == syscall.SIGUSR1)
+					return s, nil
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
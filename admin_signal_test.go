@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package clogger
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSignals(t *testing.T) {
+	l, _ := newRecordingLogger()
+	l.SetLevel(SeverityInfo)
+
+	stop := HandleSignals(l)
+	defer stop()
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return l.Level() == SeverityDebug }, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	assert.Eventually(t, func() bool { return l.Level() == SeverityInfo }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,6 @@
+package clogger
+
+// HandleSignals is a no-op on Windows, which has no SIGUSR1 and SIGUSR2.
+func HandleSignals(l *DefaultLogger) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,157 @@
+package clogger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetLevel(SeverityWarn)
+
+	ctx := WithLogger(context.Background(), l)
+	Info(ctx, "Dropped")
+	Warn(ctx, "Kept")
+	_, ev := NewEvent(ctx, "dropped")
+	ev.End()
+
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, "Kept", sink.entries[0].message)
+	assert.Empty(t, sink.events)
+}
+
+func TestAdminHandler(t *testing.T) {
+	l, sink := newRecordingLogger()
+	h := NewAdminHandler(l)
+
+	serve := func(method, body string) (*httptest.ResponseRecorder, adminState) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/logging", strings.NewReader(body)))
+
+		var state adminState
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+		}
+
+		return rec, state
+	}
+
+	rec, state := serve(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, state.SampleRate)
+
+	rec, state = serve(http.MethodPut, `{"level":"warning","verbosity":2,"vmodule":"server=3","sample_rate":0.5}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, SeverityWarn, l.Level())
+	assert.Equal(t, 2, l.Verbosity())
+	assert.Equal(t, "server=3", l.VModule())
+	assert.Equal(t, SeverityWarn, state.Level)
+	require.NotNil(t, state.SampleRate)
+	assert.InDelta(t, 0.5, *state.SampleRate, 0.0001)
+
+	require.Len(t, sink.entries, 1, "Changes should be logged whatever the level")
+	assert.Equal(t, SeverityNotice, sink.entries[0].severity)
+	assert.Equal(t, "admin", sink.entries[0].fields.retrieve("source"))
+
+	for _, body := range []string{
+		`{"level":"loud"}`,
+		`{"level":"debug","vmodule":"server"}`,
+		`{"sample_rate":2}`,
+		`{"ttl":"-1m"}`,
+		`not json`,
+	} {
+		rec, _ = serve(http.MethodPost, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+	assert.Equal(t, SeverityWarn, l.Level(), "Invalid changes should apply nothing")
+
+	rec, _ = serve(http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestAdminHandlerTTL(t *testing.T) {
+	l, _ := newRecordingLogger()
+	l.SetLevel(SeverityInfo)
+	h := NewAdminHandler(l)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"DEBUG","ttl":"50ms"}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "revert_at")
+	assert.Equal(t, SeverityDebug, l.Level())
+
+	assert.Eventually(t, func() bool {
+		return l.Level() == SeverityInfo
+	}, time.Second, 10*time.Millisecond)
+
+	// A newer change cancels the pending revert
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"DEBUG","ttl":"50ms"}`)))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"TRACE"}`)))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, SeverityTrace, l.Level())
+}
+
+func TestAdminHandlerTTLPartialRevert(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetLevel(SeverityInfo)
+	h := NewAdminHandler(l)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"DEBUG","verbosity":2,"ttl":"50ms"}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// Changed in the meantime, e.g. by a signal or a config reload
+	require.NoError(t, l.updateSettings("test", func(s settings) (settings, error) {
+		s.verbosity = 3
+		s.vmodule = "server=4"
+		return s, nil
+	}))
+
+	assert.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		last := sink.entries[len(sink.entries)-1]
+		return last.fields.retrieve("source") == "admin ttl"
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, SeverityInfo, l.Level(), "Settings left as changed should be reverted")
+	assert.Equal(t, 3, l.Verbosity(), "Settings changed since should be kept")
+	assert.Equal(t, "server=4", l.VModule(), "Settings not changed by the handler should be kept")
+}
+
+type customSampler struct{}
+
+func (customSampler) SampleLogEntry(*LogEntry) bool { return true }
+func (customSampler) SampleEvent(*Event) bool       { return true }
+
+func TestAdminHandlerCustomSampler(t *testing.T) {
+	l, _ := newRecordingLogger()
+	l.SetSampler(customSampler{})
+	h := NewAdminHandler(l)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"DEBUG","sample_rate":0.5}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "customSampler")
+	assert.Equal(t, customSampler{}, l.Sampler())
+	assert.NotEqual(t, SeverityDebug, l.Level(), "Nothing should be applied")
+}
+
+func TestStepLevel(t *testing.T) {
+	assert.Equal(t, SeverityDebug, stepLevel(SeverityInfo, true))
+	assert.Equal(t, SeverityNotice, stepLevel(SeverityInfo, false))
+	assert.Equal(t, SeverityInfo, stepLevel(SeverityInfo+50, true))
+	assert.Equal(t, SeverityTrace, stepLevel(0, false))
+	assert.Equal(t, SeverityTrace, stepLevel(SeverityTrace, true))
+	assert.Equal(t, SeverityEmergency, stepLevel(SeverityEmergency, false))
+}
@@ -0,0 +1,175 @@
+package clogger
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	baggageHeader = "baggage"
+
+	// Limits defined by https://www.w3.org/TR/baggage/#limits
+	baggageMaxBytes   = 8192
+	baggageMaxMembers = 180
+)
+
+// BaggageLabels selects the event labels which travel between services through the W3C baggage header.
+// https://www.w3.org/TR/baggage/
+type BaggageLabels struct {
+	// Keys lists the labels allowed to travel, both when sending and when receiving,
+	// by order of priority when they exceed MaxBytes.
+	Keys []string
+
+	// MaxBytes limits the size of the labels, keys and values included, sent or restored
+	// through a single header. Defaults to 8192 bytes, the W3C limit.
+	MaxBytes int
+}
+
+func (b BaggageLabels) maxBytes() int {
+	if b.MaxBytes <= 0 || b.MaxBytes > baggageMaxBytes {
+		return baggageMaxBytes
+	}
+
+	return b.MaxBytes
+}
+
+// header serializes the allowed labels of the event found in ctx, redacted as the event is, in the order
+// of Keys, appending them to the existing baggage header, if any. Members already present in the existing
+// header are kept as they are. Labels which would exceed MaxBytes are left out.
+func (b BaggageLabels) header(ctx context.Context, existing string) string {
+	ev, ok := ctx.Value(eventKey).(*Event)
+	if !ok {
+		return existing
+	}
+
+	labels := make(map[string]interface{})
+	for _, label := range redactorOf(ev.logger).fields(ev.labels) {
+		labels[label.key] = label.value
+	}
+
+	present := parseBaggage(existing)
+	members := make([]string, 0, len(b.Keys))
+	if existing != "" {
+		members = append(members, existing)
+	}
+
+	size, count := len(existing), len(present)
+	for _, key := range b.Keys {
+		value, ok := labels[key]
+		if !ok || !isBaggageKey(key) {
+			continue
+		}
+		if _, ok := present[key]; ok {
+			continue
+		}
+
+		member := key + "=" + url.PathEscape(fmt.Sprint(value))
+		if size+len(member)+1 > b.maxBytes() || count+1 > baggageMaxMembers {
+			continue
+		}
+
+		members = append(members, member)
+		size += len(member) + 1
+		count++
+	}
+
+	return strings.Join(members, ",")
+}
+
+// labels returns the allowed members of the given baggage header, see selectLabels.
+func (b BaggageLabels) labels(header string) map[string]string {
+	return b.selectLabels(parseBaggage(header))
+}
+
+// selectLabels returns the allowed labels among values, in the order of Keys, as long as they fit within MaxBytes.
+func (b BaggageLabels) selectLabels(values map[string]string) map[string]string {
+	out := make(map[string]string)
+
+	size := 0
+	for _, key := range b.Keys {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+
+		if size+len(key)+len(value) > b.maxBytes() {
+			continue
+		}
+
+		out[key] = value
+		size += len(key) + len(value)
+	}
+
+	return out
+}
+
+// parseBaggage decodes the members of a baggage header, ignoring their properties and any malformed member.
+func parseBaggage(header string) map[string]string {
+	out := make(map[string]string)
+	if header == "" || len(header) > baggageMaxBytes {
+		return out
+	}
+
+	for i, member := range strings.Split(header, ",") {
+		if i >= baggageMaxMembers {
+			break
+		}
+
+		// Properties, if any, follow the first ";"
+		if j := strings.Index(member, ";"); j >= 0 {
+			member = member[:j]
+		}
+
+		kv := strings.SplitN(member, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(kv[0])
+		value, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil || !isBaggageKey(key) {
+			continue
+		}
+
+		out[key] = value
+	}
+
+	return out
+}
+
+// isBaggageKey reports whether key is a valid RFC 7230 token.
+func isBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// contextWithBaggage returns a copy of ctx holding the received baggage header.
+func contextWithBaggage(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, baggageKey, header)
+}
+
+// WithBaggageLabels restores the allowed labels received through the W3C baggage header as labels of the new event.
+// The header is made available by HTTPMiddleware and UnaryServerInterceptor/StreamServerInterceptor.
+func WithBaggageLabels(b BaggageLabels) EventOption {
+	return func(ctx context.Context, event *Event) {
+		header, _ := ctx.Value(baggageKey).(string)
+		for key, value := range b.labels(header) {
+			event.labels.add(key, value)
+		}
+	}
+}
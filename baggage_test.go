@@ -0,0 +1,126 @@
+package clogger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestBaggageLabelsHTTP(t *testing.T) {
+	b := BaggageLabels{Keys: []string{"tenant_id", "user_id"}}
+
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithBaggageLabels(b))
+	setGlobal(t, l)
+
+	var (
+		header string
+		ev     *Event
+	)
+	srv := httptest.NewServer(HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(baggageHeader)
+		ev = r.Context().Value(eventKey).(*Event)
+	})))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: Transport(nil, WithTransportPropagator(propagation.TraceContext{}), WithTransportBaggage(b)),
+	}
+
+	ctx, caller := NewEvent(context.Background(), "Outbound call")
+	caller.SetLabel("tenant_id", "acme corp")
+	caller.SetLabel("user_id", 42)
+	caller.SetLabel("session", "not allowed")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(baggageHeader, "region=eu,tenant_id=upstream")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "region=eu,tenant_id=upstream,user_id=42", header)
+	require.NotNil(t, ev)
+	assert.Equal(t, "upstream", ev.labels.retrieve("tenant_id"))
+	assert.Equal(t, "42", ev.labels.retrieve("user_id"))
+	assert.Nil(t, ev.labels.retrieve("region"))
+}
+
+func TestBaggageLabelsGRPC(t *testing.T) {
+	b := BaggageLabels{Keys: []string{"tenant_id"}}
+	c := newGRPCConfig([]GRPCOption{WithGRPCPropagator(propagation.TraceContext{}), WithGRPCBaggage(b)})
+
+	ctx, caller := NewEvent(context.Background(), "Outbound call")
+	caller.SetLabel("tenant_id", "acme, corp;1")
+
+	out, ok := metadata.FromOutgoingContext(c.outgoingContext(ctx))
+	require.True(t, ok)
+
+	md := metadataCarrier(out)
+	assert.Equal(t, "tenant_id=acme%2C%20corp%3B1", md.Get(baggageHeader))
+	assert.Equal(t, map[string]string{"tenant_id": "acme, corp;1"}, b.labels(md.Get(baggageHeader)))
+}
+
+func TestParseBaggage(t *testing.T) {
+	got := parseBaggage(" tenant_id = acme%20corp ;prop=1, invalid, bad key=1,user_id=42")
+	assert.Equal(t, map[string]string{"tenant_id": "acme corp", "user_id": "42"}, got)
+
+	assert.Empty(t, parseBaggage(strings.Repeat("a", baggageMaxBytes+1)))
+}
+
+func TestBaggageLabelsMaxBytes(t *testing.T) {
+	b := BaggageLabels{Keys: []string{"tenant_id", "user_id"}, MaxBytes: 16}
+
+	ctx, ev := NewEvent(context.Background(), "Outbound call")
+	ev.SetLabel("tenant_id", "acme")
+	ev.SetLabel("user_id", strings.Repeat("1", 32))
+
+	assert.Equal(t, "tenant_id=acme", b.header(ctx, ""))
+	assert.Equal(t, map[string]string{"tenant_id": "acme"}, b.labels("tenant_id=acme,user_id="+strings.Repeat("1", 32)))
+}
+
+func TestBaggageLabelsRedacted(t *testing.T) {
+	b := BaggageLabels{Keys: []string{"contact"}}
+	c := newGRPCConfig([]GRPCOption{WithGRPCBaggage(b)})
+
+	l := NewDefaultLogger()
+	l.SetRedactor(NewRedactor(WithRedactedValues(MaskValue(0), DetectEmails())))
+
+	ctx, caller := l.NewEvent(context.Background(), "Outbound call")
+	caller.SetLabel("contact", "john@example.com")
+
+	out, ok := metadata.FromOutgoingContext(c.outgoingContext(ctx))
+	require.True(t, ok)
+
+	md := metadataCarrier(out)
+	assert.Equal(t, "contact=%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A%2A", md.Get(baggageHeader))
+	assert.Len(t, out, 1, "Labels should only travel through the baggage")
+}
+
+func TestBaggageLabelsKeysOrder(t *testing.T) {
+	// Only room for one of them, whatever the map order
+	b := BaggageLabels{Keys: []string{"user_id", "tenant_id"}, MaxBytes: 16}
+
+	for i := 0; i < 20; i++ {
+		ctx, ev := NewEvent(context.Background(), "Outbound call")
+		ev.SetLabel("tenant_id", "acme")
+		ev.SetLabel("user_id", "42")
+
+		assert.Equal(t, "user_id=42", b.header(ctx, ""))
+		assert.Equal(t, map[string]string{"user_id": "42"}, b.labels("tenant_id=acme,user_id=42"))
+	}
+
+	b.MaxBytes = 0
+	ctx, ev := NewEvent(context.Background(), "Outbound call")
+	ev.SetLabel("tenant_id", "acme")
+	ev.SetLabel("user_id", "42")
+	assert.Equal(t, "user_id=42,tenant_id=acme", b.header(ctx, ""), "Members should follow the order of Keys")
+}
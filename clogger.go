@@ -3,5 +3,9 @@ package clogger
 type ctxKey string
 
 const (
-	eventKey ctxKey = "event"
+	eventKey        ctxKey = "event"
+	traceContextKey ctxKey = "trace_context"
+	baggageKey      ctxKey = "baggage"
+	forceDebugKey   ctxKey = "force_debug"
+	loggerKey       ctxKey = "logger"
 )
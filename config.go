@@ -0,0 +1,592 @@
+package clogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix = "CLOGGER_"
+
+	// The env var naming the config file, when LoadConfig is given none.
+	envConfigFile = envPrefix + "CONFIG"
+)
+
+// Config describes a DefaultLogger, see FromConfig. It is usually loaded with LoadConfig.
+type Config struct {
+	// The minimum severity output. Defaults to outputting everything.
+	Level Severity
+
+	// The name of a registered encoder: json, terminal or stackdriver out of the box,
+	// see RegisterEncoder. Defaults to json.
+	Format string
+
+	// Either stdout, stderr or the path of a file to append to.
+	// Defaults to writing up to Info to stdout and the rest to stderr.
+	Output string
+
+	Verbosity int
+	VModule   string
+
+	// The rate of unsampled traces kept by a TraceSampler. Nil keeps everything.
+	SampleRate *float64
+
+	// The names of registered options, see RegisterEventOption and RegisterLogEntryOption.
+	EventOptions    []string
+	LogEntryOptions []string
+
+	// The fields whose values get masked, as globs matching their names, e.g. "*password*".
+	RedactKeys []string
+
+	// The sensitive values masked wherever they are found, out of emails, card_numbers,
+	// bearer_tokens and jwts.
+	RedactValues []string
+
+	// The levels and sampling rates overridden per NamedLogger name prefix, given as objects in files,
+	// or as prefix=value lists, e.g. "billing=warn,billing.stripe=debug". They replace every override
+	// of the logger's, those set through SetNamedLevel and SetNamedSampler included.
+	NamedLevels      map[string]Severity
+	NamedSampleRates map[string]float64
+}
+
+// ConfigError points at the invalid key of a configuration.
+type ConfigError struct {
+	// The file the key was read from, if any.
+	File string
+
+	// The key as it was given, e.g. CLOGGER_LEVEL for an env var, level in a file.
+	Key string
+
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("clogger: invalid %s in %s: %v", e.Key, e.File, e.Err)
+	}
+
+	return fmt.Sprintf("clogger: invalid %s: %v", e.Key, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// configKeys parse the keys of the config files, the env vars being the same in upper case and prefixed
+// with CLOGGER_, e.g. sample_rate and CLOGGER_SAMPLE_RATE. Lists are comma separated in env vars.
+var configKeys = []struct {
+	name  string
+	parse func(c *Config, value string) error
+}{
+	{"level", func(c *Config, value string) error {
+		sev, err := ParseSeverity(value)
+		c.Level = sev
+		return err
+	}},
+	{"format", func(c *Config, value string) error {
+		c.Format = value
+		_, err := lookupEncoder(value)
+		return err
+	}},
+	{"output", func(c *Config, value string) error {
+		c.Output = value
+		return nil
+	}},
+	{"verbosity", func(c *Config, value string) error {
+		v, err := strconv.Atoi(value)
+		c.Verbosity = v
+		return err
+	}},
+	{"vmodule", func(c *Config, value string) error {
+		c.VModule = value
+		_, err := parseVModule(value)
+		return err
+	}},
+	{"sample_rate", func(c *Config, value string) error {
+		rate, err := parseSampleRate(value)
+		if err != nil {
+			return err
+		}
+
+		c.SampleRate = &rate
+		return nil
+	}},
+	{"event_options", func(c *Config, value string) error {
+		c.EventOptions = splitList(value)
+		_, err := lookupEventOptions(c.EventOptions)
+		return err
+	}},
+	{"log_entry_options", func(c *Config, value string) error {
+		c.LogEntryOptions = splitList(value)
+		_, err := lookupLogEntryOptions(c.LogEntryOptions)
+		return err
+	}},
+	{"redact_keys", func(c *Config, value string) error {
+		c.RedactKeys = splitList(value)
+		for _, pattern := range c.RedactKeys {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+		return nil
+	}},
+	{"redact_values", func(c *Config, value string) error {
+		c.RedactValues = splitList(value)
+		_, err := lookupDetectors(c.RedactValues)
+		return err
+	}},
+	{"named_levels", func(c *Config, value string) error {
+		c.NamedLevels = make(map[string]Severity)
+		return splitPairs(value, func(prefix, value string) error {
+			sev, err := ParseSeverity(value)
+			c.NamedLevels[prefix] = sev
+			return err
+		})
+	}},
+	{"named_sample_rates", func(c *Config, value string) error {
+		c.NamedSampleRates = make(map[string]float64)
+		return splitPairs(value, func(prefix, value string) error {
+			rate, err := parseSampleRate(value)
+			c.NamedSampleRates[prefix] = rate
+			return err
+		})
+	}},
+}
+
+// Detectors available to configs, by name.
+var configDetectors = map[string]func() Detector{
+	"emails":        DetectEmails,
+	"card_numbers":  DetectCardNumbers,
+	"bearer_tokens": DetectBearerTokens,
+	"jwts":          DetectJWTs,
+}
+
+// LoadConfig reads the config file at path, JSON if it ends with .json, YAML otherwise, then overrides
+// it with the CLOGGER_* env vars, e.g. CLOGGER_LEVEL=debug or CLOGGER_EVENT_OPTIONS=trace_context.
+// Without a path, the file named by CLOGGER_CONFIG is read, if any. An empty file is an error.
+// Invalid values are reported through a *ConfigError.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+
+	if path == "" {
+		path = os.Getenv(envConfigFile)
+	}
+
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return c, err
+		}
+	}
+
+	for _, key := range configKeys {
+		name := envPrefix + strings.ToUpper(key.name)
+		if value, ok := os.LookupEnv(name); ok {
+			if err := key.parse(&c, strings.TrimSpace(value)); err != nil {
+				return c, &ConfigError{Key: name, Err: err}
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("clogger: config: %w", err)
+	}
+
+	// Most likely truncated, e.g. read while being written
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return fmt.Errorf("clogger: config %s: empty file", path)
+	}
+
+	values := make(map[string]interface{})
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(b, &values)
+	} else {
+		err = yaml.Unmarshal(b, &values)
+	}
+	if err != nil {
+		return fmt.Errorf("clogger: config %s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := c.set(key, values[key]); err != nil {
+			return &ConfigError{File: path, Key: key, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Keys taking prefix=value lists, which files may also give as objects.
+var configPairKeys = map[string]bool{
+	"named_levels":       true,
+	"named_sample_rates": true,
+}
+
+// set parses the value of a config file key, the scalars, lists and objects being handled as in env vars.
+func (c *Config) set(key string, value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		s = strings.Join(items, ",")
+	case map[string]interface{}:
+		if !configPairKeys[key] {
+			return fmt.Errorf("expecting a value or a list, not an object")
+		}
+
+		pairs := make([]string, 0, len(v))
+		for k, item := range v {
+			pairs = append(pairs, k+"="+fmt.Sprint(item))
+		}
+		sort.Strings(pairs)
+		s = strings.Join(pairs, ",")
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	for _, k := range configKeys {
+		if k.name == key {
+			return k.parse(c, strings.TrimSpace(s))
+		}
+	}
+
+	return fmt.Errorf("unknown key")
+}
+
+// FromConfig builds a DefaultLogger out of c.
+func FromConfig(c Config) (*DefaultLogger, error) {
+	l := NewDefaultLogger()
+	if err := l.configure(c); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// configure applies c to the logger, replacing whatever it configures, e.g. every named level and sampler,
+// while the named sinks are left as they are. The whole config is validated first, for an invalid one to
+// leave the logger as it is. Log entries and events already admitted
+// go through either the previous settings or the new ones, never a mix of both.
+func (l *DefaultLogger) configure(c Config) error {
+	var enc Encoder = &JSONEncoder{}
+	if c.Format != "" {
+		e, err := lookupEncoder(c.Format)
+		if err != nil {
+			return &ConfigError{Key: "format", Err: err}
+		}
+		enc = e
+	}
+
+	if _, err := parseVModule(c.VModule); err != nil {
+		return &ConfigError{Key: "vmodule", Err: err}
+	}
+
+	var sampler Sampler
+	if c.SampleRate != nil {
+		if err := checkSampleRate(*c.SampleRate); err != nil {
+			return &ConfigError{Key: "sample_rate", Err: err}
+		}
+		sampler = NewTraceSampler(*c.SampleRate)
+	}
+
+	namedLevels := make(map[string]Severity, len(c.NamedLevels))
+	for prefix, sev := range c.NamedLevels {
+		namedLevels[prefix] = sev
+	}
+
+	namedSamplers := make(map[string]Sampler, len(c.NamedSampleRates))
+	for prefix, rate := range c.NamedSampleRates {
+		if err := checkSampleRate(rate); err != nil {
+			return &ConfigError{Key: "named_sample_rates", Err: fmt.Errorf("invalid %s: %w", prefix, err)}
+		}
+		namedSamplers[prefix] = NewTraceSampler(rate)
+	}
+
+	eventOpts, err := lookupEventOptions(c.EventOptions)
+	if err != nil {
+		return &ConfigError{Key: "event_options", Err: err}
+	}
+
+	entryOpts, err := lookupLogEntryOptions(c.LogEntryOptions)
+	if err != nil {
+		return &ConfigError{Key: "log_entry_options", Err: err}
+	}
+
+	for _, pattern := range c.RedactKeys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return &ConfigError{Key: "redact_keys", Err: fmt.Errorf("invalid pattern %q: %w", pattern, err)}
+		}
+	}
+
+	detectors, err := lookupDetectors(c.RedactValues)
+	if err != nil {
+		return &ConfigError{Key: "redact_values", Err: err}
+	}
+
+	var redactor *Redactor
+	if len(c.RedactKeys) > 0 || len(detectors) > 0 {
+		redactor = NewRedactor(
+			WithRedactedKeyGlobs(MaskValue(0), c.RedactKeys...),
+			WithRedactedValues(MaskValue(0), detectors...),
+		)
+	}
+
+	// Opened last, for invalid configs not to open files
+	out, err := openOutput(c.Output)
+	if err != nil {
+		return &ConfigError{Key: "output", Err: err}
+	}
+
+	l.settingsMu.Lock()
+	defer l.settingsMu.Unlock()
+
+	l.mu.Lock()
+	l.setSettings(settings{level: c.Level, verbosity: c.Verbosity, vmodule: c.VModule, sampler: sampler})
+	l.enc = enc
+	l.redactor = redactor
+	l.eventOpts = eventOpts
+	l.logEntryOpts = entryOpts
+	l.out = out
+	l.named.levels = namedLevels
+	l.named.samplers = namedSamplers
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Files opened as outputs, by path. They are kept open and shared for the life of the process,
+// for reloaded configs not to close them under the log entries being written.
+var outputFiles = struct {
+	mu    sync.Mutex
+	files map[string]*os.File
+}{files: make(map[string]*os.File)}
+
+func openOutput(output string) (io.Writer, error) {
+	switch output {
+	case "":
+		return nil, nil
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	outputFiles.mu.Lock()
+	defer outputFiles.mu.Unlock()
+
+	if f, ok := outputFiles.files[output]; ok {
+		return f, nil
+	}
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	outputFiles.files[output] = f
+
+	return f, nil
+}
+
+func lookupDetectors(names []string) ([]Detector, error) {
+	detectors := make([]Detector, 0, len(names))
+	for _, name := range names {
+		fn, ok := configDetectors[name]
+		if !ok {
+			return nil, unknownName("detector", name, configDetectors)
+		}
+		detectors = append(detectors, fn())
+	}
+
+	return detectors, nil
+}
+
+func parseSampleRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return rate, checkSampleRate(rate)
+}
+
+func checkSampleRate(rate float64) error {
+	if rate < 0 || rate > 1 {
+		return fmt.Errorf("%v is out of range, expecting a value between 0 and 1", rate)
+	}
+
+	return nil
+}
+
+// splitPairs calls fn with the prefix and value of each pair of a prefix=value list.
+func splitPairs(value string, fn func(prefix, value string) error) error {
+	for _, item := range splitList(value) {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return fmt.Errorf("invalid %q, expecting prefix=value", item)
+		}
+
+		if err := fn(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])); err != nil {
+			return fmt.Errorf("invalid %q: %w", item, err)
+		}
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// registry holds the encoders and options that configs refer to by name.
+var registry = struct {
+	mu           sync.Mutex
+	encoders     map[string]func() Encoder
+	eventOpts    map[string]EventOption
+	logEntryOpts map[string]LogEntryOption
+}{
+	encoders: map[string]func() Encoder{
+		"json":        func() Encoder { return &JSONEncoder{} },
+		"terminal":    func() Encoder { return &TerminalEncoder{} },
+		"stackdriver": func() Encoder { return &StackdriverEncoder{} },
+	},
+	eventOpts: map[string]EventOption{
+		"trace_context":      WithTraceContext(),
+		"opentelemetry":      WithOpenTelemetryTrace(),
+		"opentelemetry_span": WithOpenTelemetryEventSpan(),
+		"opencensus":         WithOpenCensusTrace(),
+	},
+	logEntryOpts: map[string]LogEntryOption{
+		"trace_context":             WithTraceContextSpan(),
+		"opentelemetry":             WithOpenTelemetrySpan(),
+		"opentelemetry_span_events": WithOpenTelemetrySpanEvents(),
+		"opencensus":                WithOpenCensusSpan(),
+	},
+}
+
+// RegisterEncoder makes an encoder available to configs under name, replacing any previous one.
+// The json, terminal and stackdriver encoders are registered out of the box.
+func RegisterEncoder(name string, fn func() Encoder) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.encoders[name] = fn
+}
+
+// RegisterEventOption makes an event option available to configs under name, replacing any previous one.
+// Out of the box: trace_context, opentelemetry, opentelemetry_span and opencensus.
+func RegisterEventOption(name string, opt EventOption) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.eventOpts[name] = opt
+}
+
+// RegisterLogEntryOption makes a log entry option available to configs under name, replacing any previous one.
+// Out of the box: trace_context, opentelemetry, opentelemetry_span_events and opencensus.
+func RegisterLogEntryOption(name string, opt LogEntryOption) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.logEntryOpts[name] = opt
+}
+
+func lookupEncoder(name string) (Encoder, error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	fn, ok := registry.encoders[name]
+	if !ok {
+		return nil, unknownName("format", name, registry.encoders)
+	}
+
+	return fn(), nil
+}
+
+func lookupEventOptions(names []string) ([]EventOption, error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	opts := make([]EventOption, 0, len(names))
+	for _, name := range names {
+		opt, ok := registry.eventOpts[name]
+		if !ok {
+			return nil, unknownName("event option", name, registry.eventOpts)
+		}
+		opts = append(opts, opt)
+	}
+
+	return opts, nil
+}
+
+func lookupLogEntryOptions(names []string) ([]LogEntryOption, error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	opts := make([]LogEntryOption, 0, len(names))
+	for _, name := range names {
+		opt, ok := registry.logEntryOpts[name]
+		if !ok {
+			return nil, unknownName("log entry option", name, registry.logEntryOpts)
+		}
+		opts = append(opts, opt)
+	}
+
+	return opts, nil
+}
+
+// unknownName lists the registered names, the keys of m, in the error.
+func unknownName(kind, name string, m interface{}) error {
+	var names []string
+	switch m := m.(type) {
+	case map[string]func() Encoder:
+		for n := range m {
+			names = append(names, n)
+		}
+	case map[string]EventOption:
+		for n := range m {
+			names = append(names, n)
+		}
+	case map[string]LogEntryOption:
+		for n := range m {
+			names = append(names, n)
+		}
+	case map[string]func() Detector:
+		for n := range m {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("unknown %s %q, expecting one of %s", kind, name, strings.Join(names, ", "))
+}
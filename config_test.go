@@ -0,0 +1,142 @@
+package clogger
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, "logger.yaml", `
+level: notice
+format: terminal
+verbosity: 2
+vmodule: server=3
+sample_rate: 0.5
+event_options: [trace_context]
+log_entry_options:
+  - trace_context
+`)
+	t.Setenv("CLOGGER_LEVEL", "debug")
+	t.Setenv("CLOGGER_OUTPUT", "stderr")
+
+	c, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, SeverityDebug, c.Level, "Env vars should override the file")
+	assert.Equal(t, "terminal", c.Format)
+	assert.Equal(t, "stderr", c.Output)
+	assert.Equal(t, 2, c.Verbosity)
+	assert.Equal(t, "server=3", c.VModule)
+	require.NotNil(t, c.SampleRate)
+	assert.Equal(t, 0.5, *c.SampleRate)
+	assert.Equal(t, []string{"trace_context"}, c.EventOptions)
+	assert.Equal(t, []string{"trace_context"}, c.LogEntryOptions)
+
+	t.Setenv("CLOGGER_CONFIG", writeConfig(t, "logger.json", `{"level": 400, "event_options": ["opentelemetry", "trace_context"]}`))
+	os.Unsetenv("CLOGGER_LEVEL")
+
+	c, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityWarn, c.Level)
+	assert.Equal(t, []string{"opentelemetry", "trace_context"}, c.EventOptions)
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, tt := range []struct {
+		file, env, value string
+		key              string
+	}{
+		{file: "format: xml", key: "format"},
+		{file: "levl: debug", key: "levl"},
+		{file: "sample_rate: 2", key: "sample_rate"},
+		{file: "event_options: [unknown]", key: "event_options"},
+		{file: "vmodule: {server: 2}", key: "vmodule"},
+		{env: "CLOGGER_LEVEL", value: "loud", key: "CLOGGER_LEVEL"},
+		{env: "CLOGGER_VERBOSITY", value: "high", key: "CLOGGER_VERBOSITY"},
+		{env: "CLOGGER_VMODULE", value: "server", key: "CLOGGER_VMODULE"},
+	} {
+		path := ""
+		if tt.file != "" {
+			path = writeConfig(t, "logger.yml", tt.file)
+		}
+		if tt.env != "" {
+			t.Setenv(tt.env, tt.value)
+		}
+
+		_, err := LoadConfig(path)
+
+		var ce *ConfigError
+		require.True(t, errors.As(err, &ce), "%v", err)
+		assert.Equal(t, tt.key, ce.Key)
+		assert.Equal(t, path, ce.File)
+
+		if tt.env != "" {
+			os.Unsetenv(tt.env)
+		}
+	}
+
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestFromConfig(t *testing.T) {
+	rate := 0.25
+	out := filepath.Join(t.TempDir(), "out.log")
+
+	l, err := FromConfig(Config{
+		Level:        SeverityWarn,
+		Format:       "stackdriver",
+		Output:       out,
+		Verbosity:    1,
+		SampleRate:   &rate,
+		EventOptions: []string{"trace_context"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, SeverityWarn, l.Level())
+	assert.IsType(t, &StackdriverEncoder{}, l.enc)
+	assert.Equal(t, 1, l.Verbosity())
+	assert.InDelta(t, 0.25, l.Sampler().(*TraceSampler).Rate(), 0.0001)
+	assert.Len(t, l.EventOptions(), 1)
+
+	setGlobal(t, l)
+	Info(context.Background(), "Dropped")
+	Error(context.Background(), "Written")
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "Dropped")
+	assert.Contains(t, string(b), "Written")
+
+	_, err = FromConfig(Config{Format: "xml"})
+	var ce *ConfigError
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, "format", ce.Key)
+	assert.Contains(t, err.Error(), "expecting one of json, stackdriver, terminal")
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("custom", func() Encoder { return &JSONEncoder{} })
+	defer func() {
+		registry.mu.Lock()
+		delete(registry.encoders, "custom")
+		registry.mu.Unlock()
+	}()
+
+	l, err := FromConfig(Config{Format: "custom"})
+	require.NoError(t, err)
+	assert.IsType(t, &JSONEncoder{}, l.enc)
+}
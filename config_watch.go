@@ -0,0 +1,141 @@
+package clogger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// WatchConfig configures l from the config file at path, see LoadConfig, then reloads it whenever
+// the file changes, checked every interval, or on SIGHUP. Without a path, CLOGGER_CONFIG is watched.
+// The interval must be positive. A changed file is only reloaded once its size and modification time
+// stayed the same for a whole interval, so that it is not read while being written; replacing it with
+// a rename is safer still.
+//
+// The config owns what it configures: the level, verbosity, sampling, encoder, output, options,
+// redaction rules and per name levels and sampling, which are swapped at once. Settings missing
+// from a reloaded file are reset to their defaults. An invalid or empty config leaves the previous
+// one in place; it is returned the first time, then reported to the ErrorHandler. Successful
+// reloads get logged. Call stop to stop watching.
+func WatchConfig(l *DefaultLogger, path string, interval time.Duration) (stop func(), err error) {
+	if path == "" {
+		path = os.Getenv(envConfigFile)
+	}
+	if path == "" {
+		return nil, errors.New("clogger: no config file to watch")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("clogger: invalid config watch interval %s, expecting a positive one", interval)
+	}
+
+	w := &configWatcher{l: l, path: path}
+	if err := w.reload(); err != nil {
+		return nil, err
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			var err error
+			select {
+			case <-ticker.C:
+				err = w.poll()
+			case <-hup:
+				err = w.reload()
+			case <-done:
+				return
+			}
+
+			if err != nil {
+				l.reportError(fmt.Errorf("clogger: config reload, keeping the previous one: %w", err))
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		ticker.Stop()
+		close(done)
+	}, nil
+}
+
+type configWatcher struct {
+	l    *DefaultLogger
+	path string
+
+	// The file as of the last reload, to detect changes.
+	loaded fileStat
+
+	// The changed file as of the previous poll, reloaded if still the same on the next one.
+	pending *fileStat
+}
+
+// fileStat identifies a version of a file.
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) (fileStat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}, err
+	}
+
+	return fileStat{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func (s fileStat) equal(o fileStat) bool {
+	return s.modTime.Equal(o.modTime) && s.size == o.size
+}
+
+// poll reloads the file once it changed since the last reload, then stayed the same since the previous poll.
+func (w *configWatcher) poll() error {
+	stat, err := statFile(w.path)
+	if err != nil || stat.equal(w.loaded) {
+		// Missing in between writes, e.g. when replaced
+		w.pending = nil
+		return nil
+	}
+
+	if w.pending == nil || !w.pending.equal(stat) {
+		w.pending = &stat
+		return nil
+	}
+
+	return w.reload()
+}
+
+func (w *configWatcher) reload() error {
+	// Even an invalid file is not reloaded until it changes again
+	if stat, err := statFile(w.path); err == nil {
+		w.loaded = stat
+	}
+	w.pending = nil
+
+	c, err := LoadConfig(w.path)
+	if err != nil {
+		return err
+	}
+
+	if err := w.l.configure(c); err != nil {
+		return err
+	}
+
+	entry := newLogEntry()
+	entry.severity = SeverityNotice
+	entry.message = "Logger config reloaded"
+	entry.template = entry.message
+	entry.fields.add("file", w.path)
+	w.l.writeLogEntry(entry)
+
+	return nil
+}
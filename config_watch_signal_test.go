@@ -0,0 +1,29 @@
+//go:build !windows
+// +build !windows
+
+package clogger
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchConfigSIGHUP(t *testing.T) {
+	path := writeConfig(t, "logger.json", `{"level": "error"}`)
+
+	l, _ := newRecordingLogger()
+
+	stop, err := WatchConfig(l, path, time.Hour)
+	require.NoError(t, err)
+	defer stop()
+	assert.Equal(t, SeverityError, l.Level())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"level": "debug"}`), 0600))
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool { return l.Level() == SeverityDebug }, time.Second, 10*time.Millisecond)
+}
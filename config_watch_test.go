@@ -0,0 +1,101 @@
+package clogger
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchConfig(t *testing.T) {
+	path := writeConfig(t, "logger.yaml", "level: info\n")
+
+	l, _ := newRecordingLogger()
+
+	w := &configWatcher{l: l, path: path}
+	require.NoError(t, w.reload())
+	assert.Equal(t, SeverityInfo, l.Level())
+
+	// Log concurrently, for the race detector to catch torn swaps
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	defer wg.Wait()
+	defer close(done)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				l.StreamLogEntry(newLogEntry().log(context.Background(), SeverityError, "In flight"))
+			}
+		}
+	}()
+
+	require.NoError(t, w.poll())
+	assert.Nil(t, w.pending, "An unchanged file should not be reloaded")
+
+	require.NoError(t, os.WriteFile(path, []byte("level: warning\nformat: terminal\nredact_keys: [password]\n"), 0600))
+	require.NoError(t, w.poll())
+	assert.Equal(t, SeverityInfo, l.Level(), "A changed file should only be reloaded once it stays the same")
+	require.NoError(t, w.poll())
+	assert.Equal(t, SeverityWarn, l.Level())
+	assert.IsType(t, &TerminalEncoder{}, l.pipeline("").enc)
+	assert.NotNil(t, l.Redactor())
+
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+	require.NoError(t, w.poll())
+	assert.Error(t, w.poll(), "An empty file should be invalid")
+	assert.Equal(t, SeverityWarn, l.Level(), "An empty file should keep the previous config")
+	assert.IsType(t, &TerminalEncoder{}, l.pipeline("").enc)
+
+	require.NoError(t, w.poll(), "An invalid file should not be reloaded until it changes again")
+
+	require.NoError(t, os.WriteFile(path, []byte("level: loud\n"), 0600))
+	require.NoError(t, w.poll())
+	err := w.poll()
+
+	var ce *ConfigError
+	assert.True(t, errors.As(err, &ce))
+	assert.Equal(t, SeverityWarn, l.Level(), "A bad reload should keep the previous config")
+	assert.IsType(t, &TerminalEncoder{}, l.pipeline("").enc)
+}
+
+func TestWatchConfigRename(t *testing.T) {
+	path := writeConfig(t, "logger.yaml", "level: info\n")
+
+	l, _ := newRecordingLogger()
+
+	stop, err := WatchConfig(l, path, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer stop()
+	assert.Equal(t, SeverityInfo, l.Level())
+
+	tmp := filepath.Join(filepath.Dir(path), "logger.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("level: warning\n"), 0600))
+	require.NoError(t, os.Rename(tmp, path))
+	assert.Eventually(t, func() bool { return l.Level() == SeverityWarn }, time.Second, 10*time.Millisecond)
+}
+
+func TestWatchConfigInvalid(t *testing.T) {
+	_, err := WatchConfig(NewDefaultLogger(), writeConfig(t, "logger.yaml", "format: xml"), time.Second)
+
+	var ce *ConfigError
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, "format", ce.Key)
+
+	_, err = WatchConfig(NewDefaultLogger(), writeConfig(t, "logger.yaml", " \n"), time.Second)
+	assert.Error(t, err)
+
+	_, err = WatchConfig(NewDefaultLogger(), writeConfig(t, "logger.yaml", "level: info\n"), 0)
+	assert.Error(t, err, "A non-positive interval should be refused rather than panic")
+}
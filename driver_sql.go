@@ -0,0 +1,398 @@
+package clogger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	// Roll-up of all database calls made during an event's lifecycle.
+	sqlStatsKey = "db"
+)
+
+type SQLOption func(c *sqlConfig)
+
+type sqlConfig struct {
+	redactArgs    bool
+	slowThreshold time.Duration
+}
+
+// WithSQLRedactedArgs records every statement argument as a placeholder instead of its value.
+func WithSQLRedactedArgs() SQLOption {
+	return func(c *sqlConfig) {
+		c.redactArgs = true
+	}
+}
+
+// WithSQLSlowThreshold raises the severity of calls taking longer than d to Warning.
+func WithSQLSlowThreshold(d time.Duration) SQLOption {
+	return func(c *sqlConfig) {
+		c.slowThreshold = d
+	}
+}
+
+func newSQLConfig(opts []SQLOption) *sqlConfig {
+	c := &sqlConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// sqlStats is stored on the event and replaced (never mutated) on each call.
+type sqlStats struct {
+	queries   int
+	totalTime time.Duration
+}
+
+func (s sqlStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"queries":    s.queries,
+		"total_time": s.totalTime.String(),
+	})
+}
+
+// WrapDriver wraps d so that every query, exec and transaction made through it gets logged.
+// If the call's context holds an Event, the log entry becomes one of its child logs and
+// the event keeps track of the number of calls and of the total time spent in the database.
+func WrapDriver(d driver.Driver, opts ...SQLOption) driver.Driver {
+	return &sqlDriver{Driver: d, cfg: newSQLConfig(opts)}
+}
+
+// WrapConnector wraps c in the same way as WrapDriver, to be used with sql.OpenDB.
+func WrapConnector(c driver.Connector, opts ...SQLOption) driver.Connector {
+	return &sqlConnector{Connector: c, cfg: newSQLConfig(opts)}
+}
+
+type sqlDriver struct {
+	driver.Driver
+	cfg *sqlConfig
+}
+
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConn{Conn: conn, cfg: d.cfg}, nil
+}
+
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+	dc, ok := d.Driver.(driver.DriverContext)
+	if !ok {
+		return &sqlConnector{Connector: dsnConnector{name: name, driver: d.Driver}, cfg: d.cfg}, nil
+	}
+
+	c, err := dc.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConnector{Connector: c, cfg: d.cfg}, nil
+}
+
+// dsnConnector is used for drivers which do not implement driver.DriverContext.
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type sqlConnector struct {
+	driver.Connector
+	cfg *sqlConfig
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlConn{Conn: conn, cfg: c.cfg}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return &sqlDriver{Driver: c.Connector.Driver(), cfg: c.cfg}
+}
+
+type sqlConn struct {
+	driver.Conn
+	cfg *sqlConfig
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if cp, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = cp.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlStmt{Stmt: stmt, query: query, cfg: c.cfg}, nil
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+
+	var (
+		tx  driver.Tx
+		err error
+	)
+	if cb, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = cb.BeginTx(ctx, opts)
+	} else if err = checkTxOptions(opts); err == nil {
+		tx, err = c.Conn.Begin()
+	}
+	c.cfg.record(ctx, "begin", "BEGIN", nil, start, nil, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlTx{Tx: tx, ctx: ctx, cfg: c.cfg}, nil
+}
+
+// checkTxOptions rejects the options a driver.Conn without driver.ConnBeginTx can't honor,
+// as database/sql does for such connections.
+func checkTxOptions(opts driver.TxOptions) error {
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return errors.New("sql: driver does not support read-only transactions")
+	}
+
+	return nil
+}
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	c.cfg.record(ctx, "exec", query, args, start, res, err)
+
+	return res, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.cfg.record(ctx, "query", query, args, start, nil, err)
+
+	return rows, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+// IsValid lets database/sql discard the connections the wrapped one reports as bad.
+func (c *sqlConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type sqlStmt struct {
+	driver.Stmt
+	query string
+	cfg   *sqlConfig
+}
+
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var (
+		res driver.Result
+		err error
+	)
+	if se, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = se.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(values(args))
+	}
+	s.cfg.record(ctx, "exec", s.query, args, start, res, err)
+
+	return res, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if sq, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = sq.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(values(args))
+	}
+	s.cfg.record(ctx, "query", s.query, args, start, nil, err)
+
+	return rows, err
+}
+
+type sqlTx struct {
+	driver.Tx
+	ctx context.Context
+	cfg *sqlConfig
+}
+
+func (t *sqlTx) Commit() error {
+	start := time.Now()
+	err := t.Tx.Commit()
+	t.cfg.record(t.ctx, "commit", "COMMIT", nil, start, nil, err)
+
+	return err
+}
+
+func (t *sqlTx) Rollback() error {
+	start := time.Now()
+	err := t.Tx.Rollback()
+	t.cfg.record(t.ctx, "rollback", "ROLLBACK", nil, start, nil, err)
+
+	return err
+}
+
+// record logs a single database call and rolls it up onto the event found in ctx, if any.
+func (c *sqlConfig) record(ctx context.Context, op, query string, args []driver.NamedValue, start time.Time, res driver.Result, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	elapsed := time.Since(start)
+
+	entry := newLogEntry()
+	entry.With("db_statement", query).
+		With("duration", elapsed.String())
+
+	if len(args) > 0 {
+		entry.With("db_args", c.args(args))
+	}
+
+	if res != nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			entry.With("db_rows_affected", n)
+		}
+	}
+
+	sev := SeverityDebug
+	switch {
+	case err != nil:
+		sev = SeverityError
+		entry.With("error", err.Error())
+	case c.slowThreshold > 0 && elapsed >= c.slowThreshold:
+		sev = SeverityWarn
+		entry.With("db_slow", true)
+	}
+
+	if ev, ok := ctx.Value(eventKey).(*Event); ok {
+		ev.fields.update(sqlStatsKey, func(value interface{}) interface{} {
+			stats, _ := value.(sqlStats)
+			stats.queries++
+			stats.totalTime += elapsed
+			return stats
+		})
+	}
+
+	entry.log(ctx, sev, "SQL "+op).dispatch()
+}
+
+func (c *sqlConfig) args(args []driver.NamedValue) []interface{} {
+	out := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if c.redactArgs {
+			out = append(out, redactedValue)
+			continue
+		}
+
+		out = append(out, arg.Value)
+	}
+
+	return out
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	out := make([]driver.NamedValue, 0, len(args))
+	for i, v := range args {
+		out = append(out, driver.NamedValue{Ordinal: i + 1, Value: v})
+	}
+
+	return out
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	out := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		out = append(out, arg.Value)
+	}
+
+	return out
+}
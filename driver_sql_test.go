@@ -0,0 +1,164 @@
+package clogger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver answers every query with a single row, every exec with 3 affected rows.
+// Statements containing "SLEEP" take 20ms, the ones containing "FAIL" return an error.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := fakeStatement(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := fakeStatement(query); err != nil {
+		return nil, err
+	}
+	return &fakeRows{}, nil
+}
+
+func fakeStatement(query string) error {
+	if strings.Contains(query, "SLEEP") {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if strings.Contains(query, "FAIL") {
+		return errors.New("syntax error")
+	}
+	return nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct{ done bool }
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func TestWrapConnector(t *testing.T) {
+	setGlobal(t, noopLogger)
+
+	db := sql.OpenDB(WrapConnector(fakeConnector{}, WithSQLSlowThreshold(10*time.Millisecond), WithSQLRedactedArgs()))
+	defer db.Close()
+
+	ctx, ev := NewEvent(context.Background(), "Updating users")
+
+	res, err := db.ExecContext(ctx, "UPDATE users SET name = ? WHERE id = ?", "john", 1)
+	require.NoError(t, err)
+	n, _ := res.RowsAffected()
+	assert.Equal(t, int64(3), n)
+
+	var id int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT id FROM users WHERE SLEEP").Scan(&id))
+	assert.Equal(t, 1, id)
+
+	_, err = db.ExecContext(ctx, "FAIL")
+	require.Error(t, err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	ev.End()
+
+	require.Len(t, ev.logs, 5)
+
+	exec := ev.logs[0]
+	assert.Equal(t, "SQL exec", exec.message)
+	assert.Equal(t, SeverityDebug, exec.severity)
+	assert.Equal(t, "UPDATE users SET name = ? WHERE id = ?", exec.fields.retrieve("db_statement"))
+	assert.Equal(t, []interface{}{redactedValue, redactedValue}, exec.fields.retrieve("db_args"))
+	assert.Equal(t, int64(3), exec.fields.retrieve("db_rows_affected"))
+
+	slow := ev.logs[1]
+	assert.Equal(t, SeverityWarn, slow.severity)
+	assert.Equal(t, true, slow.fields.retrieve("db_slow"))
+
+	failed := ev.logs[2]
+	assert.Equal(t, SeverityError, failed.severity)
+	assert.Equal(t, "syntax error", failed.fields.retrieve("error"))
+
+	assert.Equal(t, "BEGIN", ev.logs[3].fields.retrieve("db_statement"))
+	assert.Equal(t, "COMMIT", ev.logs[4].fields.retrieve("db_statement"))
+
+	stats, ok := ev.fields.retrieve(sqlStatsKey).(sqlStats)
+	require.True(t, ok)
+	assert.Equal(t, 5, stats.queries)
+	assert.True(t, stats.totalTime >= 20*time.Millisecond)
+	assert.Equal(t, SeverityError, ev.severity)
+}
+
+// registerFakeDriver registers the wrapped fakeDriver once, as sql.Register panics on duplicates e.g. with -count.
+var registerFakeDriver sync.Once
+
+func TestWrapDriver(t *testing.T) {
+	setGlobal(t, noopLogger)
+
+	registerFakeDriver.Do(func() { sql.Register("clogger-fake", WrapDriver(fakeDriver{})) })
+	db, err := sql.Open("clogger-fake", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, ev := NewEvent(context.Background(), "Reading users")
+	_, err = db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", 7)
+	require.NoError(t, err)
+	ev.End()
+
+	require.Len(t, ev.logs, 1)
+	assert.Equal(t, []interface{}{int64(7)}, ev.logs[0].fields.retrieve("db_args"))
+}
+
+type invalidConn struct{ fakeConn }
+
+func (invalidConn) IsValid() bool { return false }
+
+func TestSQLConnForwards(t *testing.T) {
+	assert.True(t, (&sqlConn{Conn: fakeConn{}, cfg: newSQLConfig(nil)}).IsValid())
+	assert.False(t, (&sqlConn{Conn: invalidConn{}, cfg: newSQLConfig(nil)}).IsValid())
+
+	c := &sqlConn{Conn: fakeConn{}, cfg: newSQLConfig(nil)}
+	_, err := c.BeginTx(context.Background(), driver.TxOptions{})
+	assert.NoError(t, err)
+	_, err = c.BeginTx(context.Background(), driver.TxOptions{Isolation: driver.IsolationLevel(sql.LevelSerializable)})
+	assert.Error(t, err, "The isolation level should not be silently dropped")
+	_, err = c.BeginTx(context.Background(), driver.TxOptions{ReadOnly: true})
+	assert.Error(t, err, "Read-only should not be silently dropped")
+}
@@ -9,9 +9,10 @@ import (
 type JSONEncoder struct{}
 
 func (j *JSONEncoder) EncodeLogEntry(entry *LogEntry) ([]byte, error) {
+	errs := &FieldsError{}
 	fields := make(map[string]interface{})
 	for _, field := range entry.fields.fields() {
-		fields[field.key] = field.value
+		fields[field.key] = safeValue{field.key, field.value, errs}
 	}
 
 	fields["message"] = entry.message
@@ -21,31 +22,43 @@ func (j *JSONEncoder) EncodeLogEntry(entry *LogEntry) ([]byte, error) {
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(fields)
 
-	return buf.Bytes(), err
+	return buf.Bytes(), errs.or(err)
 }
 
 func (j *JSONEncoder) EncodeEvent(event *Event) ([]byte, error) {
+	errs := &FieldsError{}
 	fields := make(map[string]interface{})
 
 	// Fields
+	evFields := make(map[string]interface{})
 	for _, field := range event.fields.fields() {
-		fields["fields"] = map[string]interface{}{
-			field.key: field.value,
+		switch field.key {
+		case httpRequestKey:
+			fields[httpRequestKey] = field.value
+		default:
+			evFields[field.key] = safeValue{field.key, field.value, errs}
 		}
 	}
+	if len(evFields) > 0 {
+		fields["fields"] = evFields
+	}
 
 	// Err fields
+	errFields := make(map[string]interface{})
 	for _, field := range event.errFields.fields() {
-		fields["errors"] = map[string]interface{}{
-			field.key: field.value,
-		}
+		errFields[field.key] = safeValue{field.key, field.value, errs}
+	}
+	if len(errFields) > 0 {
+		fields["errors"] = errFields
 	}
 
 	// Labels
+	labels := make(map[string]interface{})
 	for _, field := range event.labels.fields() {
-		fields["labels"] = map[string]interface{}{
-			field.key: field.value,
-		}
+		labels[field.key] = safeValue{field.key, field.value, errs}
+	}
+	if len(labels) > 0 {
+		fields["labels"] = labels
 	}
 
 	fields["message"] = event.message
@@ -56,5 +69,5 @@ func (j *JSONEncoder) EncodeEvent(event *Event) ([]byte, error) {
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(fields)
 
-	return buf.Bytes(), err
+	return buf.Bytes(), errs.or(err)
 }
@@ -54,19 +54,20 @@ func init() {
 type StackdriverEncoder struct{}
 
 func (j *StackdriverEncoder) EncodeLogEntry(entry *LogEntry) ([]byte, error) {
+	errs := &FieldsError{}
 	fields := make(map[string]interface{})
 	for _, field := range entry.fields.fields() {
 		switch field.key {
 		case opencensusSpanID, otelSpanID:
 			fields["logging.googleapis.com/spanId"] = field.value
 		case opencensusTraceID, otelTraceID:
-			// "projects/my-projectid/traces/06796866738c859f2f19b7cfb3214824"
-			// "projects/_my-project-id_/traces/_trace-id_"
-			fields["logging.googleapis.com/trace"] = fmt.Sprintf("projects/%s/traces/%s", projectID, field.value)
+			fields["logging.googleapis.com/trace"] = stackdriverTrace(field.value)
 		case opencensusSampled, otelSampled:
 			fields["logging.googleapis.com/trace_sampled"] = field.value
+		case httpRequestKey:
+			fields["httpRequest"] = stackdriverHTTPRequest(field.value)
 		default:
-			fields[field.key] = field.value
+			fields[field.key] = safeValue{field.key, field.value, errs}
 		}
 	}
 
@@ -74,47 +75,54 @@ func (j *StackdriverEncoder) EncodeLogEntry(entry *LogEntry) ([]byte, error) {
 
 	fields["message"] = entry.message
 	fields["timestamp"] = entry.timestamp.Format(time.RFC3339Nano)
-	fields["severity"] = entry.severity.String()
+	fields["severity"] = stackdriverSeverity(entry.severity)
 
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(fields)
 
-	return buf.Bytes(), err
+	return buf.Bytes(), errs.or(err)
 }
 
 func (j *StackdriverEncoder) EncodeEvent(event *Event) ([]byte, error) {
+	errs := &FieldsError{}
 	fields := make(map[string]interface{})
 
 	// Fields
+	evFields := make(map[string]interface{})
 	for _, field := range event.fields.fields() {
 		switch field.key {
 		case opencensusSpanID, otelSpanID:
 			fields["logging.googleapis.com/spanId"] = field.value
-			v, _ := field.value.(string)
-			fields["logging.googleapis.com/trace_sampled"] = v != ""
 		case opencensusTraceID, otelTraceID:
-			fields["logging.googleapis.com/trace"] = field.value
-			v, _ := field.value.(string)
-			fields["logging.googleapis.com/trace_sampled"] = v != ""
+			fields["logging.googleapis.com/trace"] = stackdriverTrace(field.value)
+		case opencensusSampled, otelSampled:
+			fields["logging.googleapis.com/trace_sampled"] = field.value
+		case httpRequestKey:
+			fields["httpRequest"] = stackdriverHTTPRequest(field.value)
 		default:
-			fields["fields"] = map[string]interface{}{
-				field.key: field.value,
-			}
+			evFields[field.key] = safeValue{field.key, field.value, errs}
 		}
 	}
+	if len(evFields) > 0 {
+		fields["fields"] = evFields
+	}
 
 	// Err fields
+	errFields := make(map[string]interface{})
 	for _, field := range event.errFields.fields() {
-		fields["errors"] = map[string]interface{}{
-			field.key: field.value,
-		}
+		errFields[field.key] = safeValue{field.key, field.value, errs}
+	}
+	if len(errFields) > 0 {
+		fields["errors"] = errFields
 	}
 
 	// Labels
+	labels := make(map[string]interface{})
 	for _, field := range event.labels.fields() {
-		fields["logging.googleapis.com/labels"] = map[string]interface{}{
-			field.key: field.value,
-		}
+		labels[field.key] = safeValue{field.key, field.value, errs}
+	}
+	if len(labels) > 0 {
+		fields["logging.googleapis.com/labels"] = labels
 	}
 
 	// TODO: sourceLocation
@@ -122,10 +130,51 @@ func (j *StackdriverEncoder) EncodeEvent(event *Event) ([]byte, error) {
 	fields["message"] = event.message
 	fields["timestamp"] = event.timestamp.Format(time.RFC3339Nano)
 	fields["latencySeconds"] = time.Since(event.timestamp).String()
-	fields["severity"] = event.severity.String()
+	fields["severity"] = stackdriverSeverity(event.severity)
 
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(fields)
 
-	return buf.Bytes(), err
+	return buf.Bytes(), errs.or(err)
+}
+
+// stackdriverTrace returns the resource name of the trace, as expected by Cloud Logging:
+// "projects/_my-project-id_/traces/_trace-id_"
+// e.g. "projects/my-projectid/traces/06796866738c859f2f19b7cfb3214824"
+func stackdriverTrace(traceID interface{}) string {
+	return fmt.Sprintf("projects/%s/traces/%s", projectID, traceID)
+}
+
+// stackdriverSeverity maps a Severity to Cloud Logging's LogSeverity, custom levels included.
+// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#logseverity
+func stackdriverSeverity(sev Severity) string {
+	switch sev.predefined() {
+	case SeverityEmergency:
+		return "EMERGENCY"
+	case SeverityAlert:
+		return "ALERT"
+	case SeverityCritical:
+		return "CRITICAL"
+	case SeverityError:
+		return "ERROR"
+	case SeverityWarn:
+		return "WARNING"
+	case SeverityNotice:
+		return "NOTICE"
+	case SeverityInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// stackdriverHTTPRequest converts an HTTPRequest into the httpRequest structure
+// that Cloud Logging expects. Any other value is passed through as is.
+func stackdriverHTTPRequest(value interface{}) interface{} {
+	req, ok := value.(*HTTPRequest)
+	if !ok || req == nil {
+		return value
+	}
+
+	return req.stackdriver()
 }
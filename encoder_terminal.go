@@ -14,9 +14,16 @@ var (
 type TerminalEncoder struct{}
 
 func (t *TerminalEncoder) EncodeLogEntry(entry *LogEntry) ([]byte, error) {
+	errs := &FieldsError{}
+	message := entry.message
 	fields := make(map[string]interface{})
 	for _, field := range entry.fields.fields() {
-		fields[field.key] = field.value
+		switch field.key {
+		case httpRequestKey:
+			message = terminalHTTPRequest(message, field.value)
+		default:
+			fields[field.key] = safeValue{field.key, field.value, errs}
+		}
 	}
 
 	buf := &bytes.Buffer{}
@@ -25,35 +32,48 @@ func (t *TerminalEncoder) EncodeLogEntry(entry *LogEntry) ([]byte, error) {
 	out := fmt.Sprintf("%s\t %s\t %s | %s",
 		time.Now().Format(timeFormatTerm),
 		entry.severity,
-		entry.message,
+		message,
 		buf.Bytes(),
 	)
 
-	return []byte(out), err
+	return []byte(out), errs.or(err)
 }
 
 func (t *TerminalEncoder) EncodeEvent(event *Event) ([]byte, error) {
+	errs := &FieldsError{}
+	message := event.message
 	fields := make(map[string]interface{})
 
 	// Fields
+	evFields := make(map[string]interface{})
 	for _, field := range event.fields.fields() {
-		fields["fields"] = map[string]interface{}{
-			field.key: field.value,
+		switch field.key {
+		case httpRequestKey:
+			message = terminalHTTPRequest(message, field.value)
+		default:
+			evFields[field.key] = safeValue{field.key, field.value, errs}
 		}
 	}
+	if len(evFields) > 0 {
+		fields["fields"] = evFields
+	}
 
 	// Err fields
+	errFields := make(map[string]interface{})
 	for _, field := range event.errFields.fields() {
-		fields["errors"] = map[string]interface{}{
-			field.key: field.value,
-		}
+		errFields[field.key] = safeValue{field.key, field.value, errs}
+	}
+	if len(errFields) > 0 {
+		fields["errors"] = errFields
 	}
 
 	// Labels
+	labels := make(map[string]interface{})
 	for _, field := range event.labels.fields() {
-		fields["labels"] = map[string]interface{}{
-			field.key: field.value,
-		}
+		labels[field.key] = safeValue{field.key, field.value, errs}
+	}
+	if len(labels) > 0 {
+		fields["labels"] = labels
 	}
 
 	fields["elapsed"] = time.Since(event.timestamp).String()
@@ -64,9 +84,15 @@ func (t *TerminalEncoder) EncodeEvent(event *Event) ([]byte, error) {
 	out := fmt.Sprintf("%s\t %s\t %s | %s",
 		time.Now().Format(timeFormatTerm),
 		event.severity,
-		event.message,
+		message,
 		buf.Bytes(),
 	)
 
-	return []byte(out), err
+	return []byte(out), errs.or(err)
+}
+
+// terminalHTTPRequest appends a short summary of the HTTP request to the message,
+// e.g. "Received a new request [GET /users 200 1.2ms]".
+func terminalHTTPRequest(message string, value interface{}) string {
+	return fmt.Sprintf("%s [%v]", message, value)
 }
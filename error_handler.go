@@ -0,0 +1,121 @@
+package clogger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// At most errorBurst internal errors get reported per errorWindow, the others are counted
+	// and reported as a single error once the window closes.
+	errorBurst  = 10
+	errorWindow = time.Minute
+)
+
+// ErrorHandler receives the internal failures of the logger: values which could not be encoded,
+// failed encodings, writes or sinks and panicking options. The logger never reports them by logging,
+// which could loop. They are rate limited, the excess being summarized once the window closes.
+type ErrorHandler func(err error)
+
+// stderrErrorHandler is the default ErrorHandler.
+func stderrErrorHandler(err error) {
+	_, _ = fmt.Fprintln(os.Stderr, err)
+}
+
+type errorReporter struct {
+	mu         sync.Mutex
+	handler    ErrorHandler
+	window     time.Duration
+	since      time.Time
+	count      int
+	suppressed int
+}
+
+func newErrorReporter() *errorReporter {
+	return &errorReporter{handler: stderrErrorHandler, window: errorWindow}
+}
+
+func (r *errorReporter) setHandler(h ErrorHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h == nil {
+		h = stderrErrorHandler
+	}
+	r.handler = h
+}
+
+func (r *errorReporter) report(err error) {
+	r.mu.Lock()
+	now := time.Now()
+
+	var summary error
+	if now.Sub(r.since) >= r.window {
+		summary = r.summary()
+		r.since, r.count, r.suppressed = now, 0, 0
+	}
+
+	r.count++
+	if r.count > errorBurst {
+		// Flush the summary once the window closes, even if no other error gets reported
+		if r.suppressed == 0 {
+			since := r.since
+			time.AfterFunc(since.Add(r.window).Sub(now), func() { r.flush(since) })
+		}
+		r.suppressed++
+		r.mu.Unlock()
+		return
+	}
+
+	h := r.handler
+	r.mu.Unlock()
+
+	if summary != nil {
+		h(summary)
+	}
+	h(err)
+}
+
+// flush reports the errors suppressed within the window opened at since, unless already reported.
+func (r *errorReporter) flush(since time.Time) {
+	r.mu.Lock()
+	if !r.since.Equal(since) {
+		r.mu.Unlock()
+		return
+	}
+
+	summary := r.summary()
+	r.since, r.count, r.suppressed = time.Time{}, 0, 0
+	h := r.handler
+	r.mu.Unlock()
+
+	if summary != nil {
+		h(summary)
+	}
+}
+
+// summary returns the error summarizing the suppressed errors, if any. r.mu must be held.
+func (r *errorReporter) summary() error {
+	if r.suppressed == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("clogger: %d more internal errors since %s", r.suppressed, r.since.Format(time.RFC3339))
+}
+
+// reportError passes err to the ErrorHandler of l, if it has one.
+func reportError(l Logger, err error) {
+	if r, ok := l.(interface{ reportError(err error) }); ok {
+		r.reportError(err)
+	}
+}
+
+// recoverOption reports the panic of an option of l instead of crashing the caller.
+// It must be deferred.
+func recoverOption(l Logger, kind string) {
+	if r := recover(); r != nil {
+		reportError(l, fmt.Errorf("clogger: %s option panicked: %v", kind, r))
+	}
+}
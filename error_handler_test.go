@@ -0,0 +1,146 @@
+package clogger
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNode struct {
+	Name string
+	Next *testNode
+}
+
+type panickingValue struct{}
+
+func (panickingValue) MarshalJSON() ([]byte, error) {
+	panic("boom")
+}
+
+func TestEncodersDegradePerField(t *testing.T) {
+	entry := newLogEntry()
+	entry.message = "Bad values"
+	entry.fields.add("ok", "kept")
+	entry.fields.add("chan", make(chan int))
+	entry.fields.add("func", func() {})
+	entry.fields.add("nan", math.NaN())
+	entry.fields.add("panic", panickingValue{})
+
+	for _, enc := range []Encoder{&JSONEncoder{}, &StackdriverEncoder{}, &TerminalEncoder{}} {
+		b, err := enc.EncodeLogEntry(entry)
+
+		var fe *FieldsError
+		require.True(t, errors.As(err, &fe), "%T", enc)
+		assert.Len(t, fe.Fields, 4)
+		assert.Contains(t, string(b), `"ok":"kept"`)
+		assert.Contains(t, string(b), `"chan":"!BADVALUE(json: unsupported type: chan int)"`)
+		assert.Contains(t, string(b), `"panic":"!BADVALUE(panic: boom)"`)
+	}
+}
+
+func TestNormalizeCyclicValue(t *testing.T) {
+	n := &testNode{Name: "a"}
+	n.Next = &testNode{Name: "b", Next: n}
+
+	got := normalizeValue(n)
+	assert.Equal(t, map[string]interface{}{
+		"Name": "a",
+		"Next": map[string]interface{}{
+			"Name": "b",
+			"Next": badValue(errCyclicValue),
+		},
+	}, got)
+
+	m := map[string]interface{}{}
+	m["self"] = m
+	assert.Equal(t, map[string]interface{}{"self": badValue(errCyclicValue)}, normalizeValue(m))
+}
+
+func TestDefaultLoggerErrorHandler(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	l, sink := newRecordingLogger()
+	l.SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	l.SetLogEntryOptions(func(ctx context.Context, entry *LogEntry) {
+		panic("bad option")
+	})
+	setGlobal(t, l)
+
+	With("chan", make(chan int)).Info(context.Background(), "Still logged")
+
+	require.Len(t, sink.entries, 1)
+	mu.Lock()
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "clogger: log entry option panicked: bad option")
+	var fe *FieldsError
+	assert.True(t, errors.As(errs[1], &fe))
+	mu.Unlock()
+}
+
+func TestErrorReporterRateLimit(t *testing.T) {
+	var errs []error
+	r := newErrorReporter()
+	r.setHandler(func(err error) { errs = append(errs, err) })
+
+	for i := 0; i < errorBurst+5; i++ {
+		r.report(errors.New("failure"))
+	}
+	assert.Len(t, errs, errorBurst)
+
+	// Open the next window
+	r.since = r.since.Add(-errorWindow)
+	r.report(errors.New("failure"))
+
+	require.Len(t, errs, errorBurst+2)
+	assert.Contains(t, errs[errorBurst].Error(), "clogger: 5 more internal errors since ")
+	assert.Equal(t, "failure", errs[errorBurst+1].Error())
+	assert.WithinDuration(t, time.Now(), r.since, time.Second)
+}
+
+func TestErrorReporterFlush(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	r := newErrorReporter()
+	r.window = 50 * time.Millisecond
+	r.setHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+
+	for i := 0; i < errorBurst+3; i++ {
+		r.report(errors.New("failure"))
+	}
+
+	// No further error gets reported, the summary should still be once the window closes
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) == errorBurst+1
+	}, time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	assert.Contains(t, errs[errorBurst].Error(), "clogger: 3 more internal errors since ")
+	mu.Unlock()
+
+	// The next error opens a new window, without any summary
+	r.report(errors.New("failure"))
+	mu.Lock()
+	require.Len(t, errs, errorBurst+2)
+	assert.Equal(t, "failure", errs[errorBurst+1].Error())
+	mu.Unlock()
+}
@@ -2,6 +2,7 @@ package clogger
 
 import (
 	"context"
+	"math/rand"
 	"runtime"
 	"sync"
 	"time"
@@ -11,6 +12,7 @@ type Eventful interface {
 	Set(key string, value interface{}) Eventful
 	SetOnErr(key string, value interface{}) Eventful
 	SetLabel(key string, value interface{}) Eventful
+	SetHTTPRequest(req *HTTPRequest) Eventful
 }
 
 // Event describes a single action that happens at a given time.
@@ -40,10 +42,49 @@ type Event struct {
 	// A label gets written/passed down to each and every log entry that it holds.
 	labels *fieldCollection
 
+	// The context the event was created with. EventOptions might decorate it,
+	// e.g. with a newly started span, before it gets returned by NewEvent.
+	ctx context.Context
+
+	// Functions to be called once the event has ended, e.g. to end a span.
+	endFns []func()
+
+	// Functions to be called once the event passed the logger's level and sampling, then got redacted,
+	// right before being written, e.g. to mirror it onto a span.
+	writeFns []func()
+
+	// Bypasses the minimum severity and sampling, see ContextWithForcedDebug.
+	forced bool
+
+	// The logger the event goes through.
+	logger Logger
+
+	// The name of the NamedLogger the event went through, if any.
+	name string
+
+	// Drawn once, never zero, for the event and its child logs to get the same sampling decision
+	// when they carry no trace ID, see TraceSampler. Derived from the event's trace ID once known,
+	// along with traceSampled, see deriveSampleKey.
+	sampleKey    uint64
+	traceSampled bool
+
+	// Set once the event has ended. The event's logs and severity are then left as they are,
+	// see End.
+	ended bool
+
+	// Guards logs, severity, ended and the hooks, as child logs might be
+	// registered concurrently e.g. by outbound HTTP calls.
+	mu   sync.Mutex
 	once sync.Once
 }
 
+// NewEvent starts an event going through the logger carried by ctx, see WithLogger, or the global one.
+// The returned context carries the event, for the log entries logged with it to become its child logs.
 func NewEvent(ctx context.Context, message string) (context.Context, *Event) {
+	return newEvent(ctx, loggerFrom(ctx), message)
+}
+
+func newEvent(ctx context.Context, l Logger, message string) (context.Context, *Event) {
 	ev := &Event{
 		message:   message,
 		timestamp: time.Now(),
@@ -52,15 +93,27 @@ func NewEvent(ctx context.Context, message string) (context.Context, *Event) {
 		labels:    newFieldCollection(),
 		fields:    newFieldCollection(),
 		errFields: newFieldCollection(),
+		ctx:       ctx,
 		once:      sync.Once{},
+		forced:    DebugForced(ctx),
+		logger:    l,
+		sampleKey: rand.Uint64() | 1,
+	}
+
+	if ev.forced {
+		ev.fields.add(debugForcedKey, true)
 	}
 
 	// Apply all decorators (modifiers) registered for this event
-	for _, opt := range logger().EventOptions() {
-		opt(ctx, ev)
+	for _, opt := range l.EventOptions() {
+		func() {
+			defer recoverOption(l, "event")
+			opt(ev.ctx, ev)
+		}()
 	}
+	ev.deriveSampleKey()
 
-	return context.WithValue(ctx, eventKey, ev), ev
+	return context.WithValue(ev.ctx, eventKey, ev), ev
 }
 
 // Set registers a key/value pair for the current event.
@@ -87,14 +140,92 @@ func (ev *Event) SetLabel(key string, value interface{}) Eventful {
 	return ev
 }
 
+// SetHTTPRequest attaches the HTTP request that this event is handling.
+// Encoders render it in a dedicated field, e.g. httpRequest in Stackdriver.
+func (ev *Event) SetHTTPRequest(req *HTTPRequest) Eventful {
+	ev.fields.add(httpRequestKey, req)
+	return ev
+}
+
+// addLog registers a child log entry and raises the event's severity, if needed.
+// It reports false if the event has already ended, the entry being left out.
+func (ev *Event) addLog(entry *LogEntry) bool {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	if ev.ended {
+		return false
+	}
+
+	ev.logs = append(ev.logs, entry)
+	entry.sampleKey, entry.traceSampled = ev.sampleKey, ev.traceSampled
+	if entry.severity > ev.severity {
+		ev.severity = entry.severity
+	}
+
+	return true
+}
+
+// deriveSampleKey derives the sampling key from the trace ID of the event, if any, for its child logs
+// to get the same sampling decision even when not stamped with the trace ID themselves.
+// ev.mu must be held, unless the event is being created.
+func (ev *Event) deriveSampleKey() {
+	if key, sampled, ok := traceSampleKey(ev.fields, ev.labels); ok {
+		ev.sampleKey, ev.traceSampled = key, sampled
+	}
+}
+
+// raiseSeverity raises the event's severity to sev, if sev is greater and the event has not ended yet.
+func (ev *Event) raiseSeverity(sev Severity) {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	if !ev.ended && sev > ev.severity {
+		ev.severity = sev
+	}
+}
+
+// onEnd registers fn to be called once the event has ended and has been streamed.
+func (ev *Event) onEnd(fn func()) {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	ev.endFns = append(ev.endFns, fn)
+}
+
+// onWrite registers fn to be called once the event is about to be written, see writeFns.
+func (ev *Event) onWrite(fn func()) {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	ev.writeFns = append(ev.writeFns, fn)
+}
+
+// writeHooks returns the functions registered by onWrite.
+func (ev *Event) writeHooks() []func() {
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+
+	return append([]func(){}, ev.writeFns...)
+}
+
 // End signals the once of the lifecycle to the event.
 // It will apply all gathered Labels onto all child log entries,
 // and it will finally output using the configured logger instance.
 // Safe to be called multiple times.
 func (ev *Event) End() {
 	ev.once.Do(func() {
+		// Settle the event under its lock, then release it before streaming, for the loggers, sinks
+		// and hooks to be able to log into the event's context without deadlocking.
+		ev.mu.Lock()
+		ev.ended = true
+
+		// The trace ID might have been set since the event got created
+		ev.deriveSampleKey()
+
 		// Process all child log entries
 		for _, entry := range ev.logs {
+			entry.sampleKey, entry.traceSampled = ev.sampleKey, ev.traceSampled
 			if ev.labels.len() > 0 {
 				// Transfer the event's Labels, if any are defined, onto each child log entry.
 				// It will overwrite existing key/value pairs from the log entry's fields.
@@ -106,15 +237,23 @@ func (ev *Event) End() {
 			if entry.severity > ev.severity {
 				ev.severity = entry.severity
 			}
+		}
 
-			// Output all child logs
-			logger().StreamLogEntry(entry)
+		logs, endFns := ev.logs, ev.endFns
+		ev.mu.Unlock()
+
+		// Output all child logs, through the logger each one is bound to
+		for _, entry := range logs {
+			entry.logger.StreamLogEntry(entry)
 		}
 
 		// The logger instance takes care of encoding
 		// and streaming the contents of an event.
-		logger().StreamEvent(ev)
+		ev.logger.StreamEvent(ev)
 
+		for _, fn := range endFns {
+			fn()
+		}
 	})
 }
 
@@ -134,7 +273,7 @@ func eventFromCtx(ctx context.Context) *Event {
 	n := runtime.Callers(2, pc)
 	frames := runtime.CallersFrames(pc[:n])
 	frame, _ := frames.Next()
-	With("caller_name", frame.Function).
+	boundLogEntry(ev.logger).With("caller_name", frame.Function).
 		Errorf(context.Background(), "Called (%s) but there was no event found in context", frame.Function)
 
 	return ev
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -100,3 +101,50 @@ func TestNewEvent(t *testing.T) {
 	assert.NotNil(t, ev.labels)
 
 }
+
+// ctxLoggingSink logs into the context of the events it receives, as a hook or a sink might.
+type ctxLoggingSink struct {
+	recordingSink
+	ctx context.Context
+}
+
+func (s *ctxLoggingSink) WriteEvent(event *Event) error {
+	Error(s.ctx, "Logged while the event is written")
+	event.raiseSeverity(SeverityCritical)
+
+	return s.recordingSink.WriteEvent(event)
+}
+
+func TestEventEndReentrant(t *testing.T) {
+	sink := &ctxLoggingSink{}
+
+	l := NewDefaultLogger()
+	l.SetSinks(sink)
+
+	ctx, ev := l.NewEvent(context.Background(), "Reentrant")
+	sink.ctx = ctx
+	ev.onWrite(func() { Info(ctx, "Logged from a write hook") })
+	Info(ctx, "Child")
+
+	done := make(chan struct{})
+	go func() {
+		ev.End()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Logging into the event's context while it ends should not deadlock")
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, SeverityInfo, sink.events[0].severity, "The severity should be settled once the event ends")
+	require.Len(t, sink.entries, 3, "Entries logged once the event ended should be output on their own")
+	assert.Equal(t, "Child", sink.entries[0].message)
+	assert.Equal(t, "Logged from a write hook", sink.entries[1].message)
+	assert.Equal(t, "Logged while the event is written", sink.entries[2].message)
+	assert.False(t, sink.entries[2].eventful)
+}
@@ -0,0 +1,216 @@
+package clogger
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// collector is a stand-in for an OTLP/HTTP collector, failing the first failures requests.
+type collector struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, r)
+	c.bodies = append(c.bodies, body)
+
+	if c.failures > 0 {
+		c.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestOTLPExporterJSON(t *testing.T) {
+	col := &collector{failures: 1}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	exp := NewOTLPExporter(srv.URL+"/v1/logs",
+		WithOTLPEncoding(OTLPEncodingJSON),
+		WithOTLPBatch(100, time.Hour),
+		WithOTLPRetries(2, time.Millisecond),
+		WithOTLPHeaders(map[string]string{"X-Api-Key": "secret"}),
+		WithOTLPResource(attribute.String("service.name", "billing")),
+	)
+
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithOpenTelemetryTrace())
+	l.SetSinks(exp)
+	setGlobal(t, l)
+
+	ctx, ev := NewEvent(tracedContext(), "Charging card")
+	ev.SetLabel("tenant_id", "acme")
+	With("amount", 42).Info(ctx, "Calling the payment provider")
+	Error(ctx, "Payment provider unavailable")
+	ev.End()
+
+	require.NoError(t, exp.Shutdown(context.Background()))
+	require.Error(t, exp.WriteLogEntry(newLogEntry()), "Writing after shutdown should fail")
+
+	require.Len(t, col.requests, 2, "The first request should be retried")
+	assert.Equal(t, "application/json", col.requests[1].Header.Get("Content-Type"))
+	assert.Equal(t, "secret", col.requests[1].Header.Get("X-Api-Key"))
+
+	var req struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []map[string]interface{} `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []map[string]interface{} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	require.NoError(t, json.Unmarshal(col.bodies[1], &req))
+	require.Len(t, req.ResourceLogs, 1)
+	assert.Equal(t, "service.name", req.ResourceLogs[0].Resource.Attributes[0]["key"])
+
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, records, 3)
+
+	info := records[0]
+	assert.Equal(t, map[string]interface{}{"stringValue": "Calling the payment provider"}, info["body"])
+	assert.Equal(t, float64(9), info["severityNumber"])
+	assert.Equal(t, "INFO", info["severityText"])
+	assert.Contains(t, info["attributes"], map[string]interface{}{"key": "amount", "value": map[string]interface{}{"intValue": "42"}})
+	assert.Contains(t, info["attributes"], map[string]interface{}{"key": "tenant_id", "value": map[string]interface{}{"stringValue": "acme"}})
+
+	assert.Equal(t, float64(17), records[1]["severityNumber"])
+
+	event := records[2]
+	assert.Equal(t, map[string]interface{}{"stringValue": "Charging card"}, event["body"])
+	assert.Equal(t, float64(17), event["severityNumber"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", event["traceId"])
+	assert.Equal(t, "00f067aa0ba902b7", event["spanId"])
+	assert.Equal(t, float64(1), event["flags"])
+	assert.Contains(t, event["attributes"], map[string]interface{}{"key": "label.tenant_id", "value": map[string]interface{}{"stringValue": "acme"}})
+}
+
+// protoField returns the first length-delimited field num found in b.
+func protoField(t *testing.T, b []byte, num protowire.Number) []byte {
+	t.Helper()
+	for len(b) > 0 {
+		n, typ, l := protowire.ConsumeTag(b)
+		require.True(t, l > 0)
+		b = b[l:]
+
+		if n == num && typ == protowire.BytesType {
+			v, l := protowire.ConsumeBytes(b)
+			require.True(t, l > 0)
+			return v
+		}
+
+		l = protowire.ConsumeFieldValue(n, typ, b)
+		require.True(t, l > 0)
+		b = b[l:]
+	}
+
+	t.Fatalf("field %d not found", num)
+	return nil
+}
+
+func TestOTLPExporterProtobuf(t *testing.T) {
+	col := &collector{}
+	srv := httptest.NewServer(col)
+	defer srv.Close()
+
+	exp := NewOTLPExporter(srv.URL+"/v1/logs", WithOTLPBatch(1, time.Hour))
+	entry := newLogEntry()
+	entry.With("user_id", "42")
+	entry.message = "User signed in"
+	entry.severity = SeverityWarn
+	require.NoError(t, exp.WriteLogEntry(entry))
+
+	require.Eventually(t, func() bool {
+		col.mu.Lock()
+		defer col.mu.Unlock()
+		return len(col.requests) == 1
+	}, time.Second, 10*time.Millisecond, "Reaching the batch size should trigger a send")
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	assert.Equal(t, "application/x-protobuf", col.requests[0].Header.Get("Content-Type"))
+
+	resourceLogs := protoField(t, col.bodies[0], 1)
+	scopeLogs := protoField(t, resourceLogs, 2)
+	scope := protoField(t, scopeLogs, 1)
+	assert.Equal(t, "github.com/foae/clogger", string(protoField(t, scope, 1)))
+
+	record := protoField(t, scopeLogs, 2)
+	assert.Equal(t, "User signed in", string(protoField(t, protoField(t, record, 5), 1)))
+	assert.Equal(t, "WARN", string(protoField(t, record, 3)))
+
+	attr := protoField(t, record, 6)
+	assert.Equal(t, "user_id", string(protoField(t, attr, 1)))
+	assert.Equal(t, "42", string(protoField(t, protoField(t, attr, 2), 1)))
+}
+
+func TestOTLPExporterNonRetryable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPExporter(srv.URL, WithOTLPRetries(5, time.Millisecond))
+	defer exp.Shutdown(context.Background())
+
+	require.NoError(t, exp.WriteLogEntry(newLogEntry()))
+	err := exp.Flush(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request")
+}
+
+func TestOTLPExporterMaxQueue(t *testing.T) {
+	exp := NewOTLPExporter("http://127.0.0.1:0", WithOTLPBatch(100, time.Hour), WithOTLPMaxQueue(2), WithOTLPRetries(0, 0))
+	defer exp.Shutdown(context.Background())
+
+	require.NoError(t, exp.WriteLogEntry(newLogEntry()))
+	require.NoError(t, exp.WriteLogEntry(newLogEntry()))
+	assert.Equal(t, errOTLPQueueFull, exp.WriteLogEntry(newLogEntry()))
+}
+
+func TestOTLPExporterShutdownCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	exp := NewOTLPExporter(srv.URL, WithOTLPBatch(1, time.Hour), WithOTLPRetries(100, time.Second))
+	require.NoError(t, exp.WriteLogEntry(newLogEntry()))
+
+	// Let the background send start retrying
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_ = exp.Shutdown(ctx)
+	assert.Less(t, time.Since(start), time.Second, "Shutdown should be bounded by its context")
+}
+
+func TestOTLPValueUint(t *testing.T) {
+	assert.Equal(t, otlpAnyValue{kind: otlpInt, intV: 42}, otlpValue(uint(42)))
+	assert.Equal(t, otlpAnyValue{kind: otlpInt, intV: math.MaxInt64}, otlpValue(uint64(math.MaxInt64)))
+	assert.Equal(t, otlpAnyValue{kind: otlpString, str: "18446744073709551615"}, otlpValue(uint64(math.MaxUint64)))
+}
@@ -1,6 +1,7 @@
 package clogger
 
 import (
+	"sort"
 	"sync"
 )
 
@@ -21,7 +22,10 @@ func newFieldCollection() *fieldCollection {
 	}
 }
 
+// add sets the field, normalizing its value, see normalizeField.
 func (fc *fieldCollection) add(key string, value interface{}) {
+	value = normalizeField(value)
+
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
 
@@ -42,7 +46,19 @@ func (fc *fieldCollection) retrieve(key string) interface{} {
 	return fc.m[key]
 }
 
+// update replaces the value stored under key with the one returned by fn,
+// which receives the current value (nil if missing). The lock is held throughout,
+// making read-modify-write operations such as counters safe.
+func (fc *fieldCollection) update(key string, fn func(value interface{}) interface{}) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.m[key] = normalizeField(fn(fc.m[key]))
+}
+
 func (fc *fieldCollection) addField(f field) {
+	f.value = normalizeField(f.value)
+
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
 
@@ -58,6 +74,7 @@ func (fc *fieldCollection) merge(f *fieldCollection) {
 	}
 }
 
+// fields returns the fields sorted by key, for a stable order.
 func (fc *fieldCollection) fields() []field {
 	fc.mu.Lock()
 	defer fc.mu.Unlock()
@@ -66,6 +83,23 @@ func (fc *fieldCollection) fields() []field {
 	for k, v := range fc.m {
 		out = append(out, field{k, v})
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].key < out[j].key })
 
 	return out
 }
+
+// rewrite replaces every value with the one returned by fn, dropping the fields for which fn returns false.
+func (fc *fieldCollection) rewrite(fn func(key string, value interface{}) (interface{}, bool)) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	for k, v := range fc.m {
+		nv, ok := fn(k, v)
+		if !ok {
+			delete(fc.m, k)
+			continue
+		}
+
+		fc.m[k] = nv
+	}
+}
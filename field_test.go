@@ -20,6 +20,18 @@ func Test_fieldCollection_addField(t *testing.T) {
 	require.Empty(t, fc.retrieve("some-unknown-key"))
 }
 
+func Test_fieldCollection_update(t *testing.T) {
+	fc := newFieldCollection()
+	incr := func(value interface{}) interface{} {
+		n, _ := value.(int)
+		return n + 1
+	}
+
+	fc.update("counter", incr)
+	fc.update("counter", incr)
+	require.Equal(t, 2, fc.retrieve("counter"))
+}
+
 func Test_fieldCollection_fields(t *testing.T) {
 	fc := newFieldCollection()
 	fs := []field{
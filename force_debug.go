@@ -0,0 +1,102 @@
+package clogger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	debugForcedKey = "debug_forced"
+
+	// The furthest expiry accepted for the header registered by WithDebugHeader, by default.
+	defaultDebugMaxTTL = time.Hour
+)
+
+// ContextWithForcedDebug flags ctx so that the event created from it, along with its child logs,
+// and the log entries logged with it bypass the logger's minimum severity and sampling.
+// The event is marked with a debug_forced field.
+func ContextWithForcedDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceDebugKey, true)
+}
+
+// DebugForced reports whether ctx has been flagged by ContextWithForcedDebug.
+func DebugForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceDebugKey).(bool)
+	return forced
+}
+
+// SignDebugHeader returns a value for the header registered by WithDebugHeader, valid for the requests
+// made to host (as found in their Host header, e.g. "api.example.com") until expiry.
+// Expiries further than the middleware's max TTL, an hour by default, are rejected.
+func SignDebugHeader(key []byte, host string, expiry time.Time) string {
+	ts := strconv.FormatInt(expiry.Unix(), 10)
+
+	return ts + "." + debugSignature(key, host, ts)
+}
+
+// verifyDebugHeader checks that the value was signed with key for host, and that it expires within maxTTL.
+func verifyDebugHeader(key []byte, host, value string, now time.Time, maxTTL time.Duration) bool {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	expiry, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || now.Unix() > expiry || expiry > now.Add(maxTTL).Unix() {
+		return false
+	}
+
+	return hmac.Equal([]byte(parts[1]), []byte(debugSignature(key, host, parts[0])))
+}
+
+func debugSignature(key []byte, host, ts string) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(strings.ToLower(host) + "\n" + ts))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// forceDebug tells whether the request asks for debug output, through one of the configured header or query param.
+func (m *httpMiddleware) forceDebug(r *http.Request) bool {
+	if m.debugHeader != "" {
+		if value := r.Header.Get(m.debugHeader); value != "" && verifyDebugHeader(m.debugKey, r.Host, value, time.Now(), m.debugMaxTTL) {
+			return true
+		}
+	}
+
+	if m.debugParam != "" {
+		if value := r.URL.Query().Get(m.debugParam); value != "" {
+			if _, ok := m.debugValues[value]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// loggedURL returns u as it gets logged, without the query param registered by WithDebugQueryParam,
+// whose values are secrets.
+func (m *httpMiddleware) loggedURL(u *url.URL) string {
+	if m.debugParam == "" {
+		return u.String()
+	}
+
+	query := u.Query()
+	if _, ok := query[m.debugParam]; !ok {
+		return u.String()
+	}
+	query.Del(m.debugParam)
+
+	c := *u
+	c.RawQuery = query.Encode()
+
+	return c.String()
+}
@@ -0,0 +1,128 @@
+package clogger
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForcedDebug(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetLevel(SeverityWarn)
+	l.SetSampler(NewTraceSampler(0))
+	setGlobal(t, l)
+
+	ctx := context.Background()
+	Debug(ctx, "Dropped")
+	assert.Empty(t, sink.entries)
+
+	ctx, ev := NewEvent(ContextWithForcedDebug(ctx), "forced")
+	Debug(ctx, "Kept")
+	ev.End()
+
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, "Kept", sink.entries[0].message)
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, true, sink.events[0].fields.retrieve(debugForcedKey))
+}
+
+func TestVerifyDebugHeader(t *testing.T) {
+	key := []byte("secret")
+	now := time.Unix(1700000000, 0)
+
+	value := SignDebugHeader(key, "api.example.com", now.Add(time.Minute))
+	assert.True(t, verifyDebugHeader(key, "api.example.com", value, now, time.Hour))
+	assert.True(t, verifyDebugHeader(key, "API.example.com", value, now, time.Hour))
+	assert.False(t, verifyDebugHeader(key, "admin.example.com", value, now, time.Hour), "Other host")
+	assert.False(t, verifyDebugHeader(key, "api.example.com", value, now.Add(2*time.Minute), time.Hour), "Expired")
+	assert.False(t, verifyDebugHeader([]byte("other"), "api.example.com", value, now, time.Hour))
+	assert.False(t, verifyDebugHeader(key, "api.example.com", "1700000060.deadbeef", now, time.Hour))
+	assert.False(t, verifyDebugHeader(key, "api.example.com", "forever", now, time.Hour))
+
+	value = SignDebugHeader(key, "api.example.com", now.Add(24*time.Hour))
+	assert.False(t, verifyDebugHeader(key, "api.example.com", value, now, time.Hour), "Over the max TTL")
+	assert.True(t, verifyDebugHeader(key, "api.example.com", value, now, 48*time.Hour))
+}
+
+func TestWithDebugHeaderEmptyKey(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetLevel(SeverityError)
+
+	for _, opt := range []HTTPOption{
+		WithDebugHeader("X-Debug", nil),
+		WithDebugHeader("X-Debug", []byte{}),
+		WithDebugHeader("", []byte("secret")),
+	} {
+		h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Debug(r.Context(), "Handling")
+		}), opt)
+
+		// Signed with the empty key, which must not be accepted
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Debug", SignDebugHeader(nil, r.Host, time.Now().Add(time.Minute)))
+		h.ServeHTTP(httptest.NewRecorder(), r.WithContext(WithLogger(r.Context(), l)))
+	}
+
+	assert.Empty(t, sink.entries)
+	assert.Empty(t, sink.events)
+}
+
+func TestHTTPMiddlewareForcedDebug(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetLevel(SeverityError)
+	setGlobal(t, l)
+
+	key := []byte("secret")
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Debug(r.Context(), "Handling")
+	}), WithDebugHeader("X-Debug", key), WithDebugQueryParam("debug", "token"))
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/?debug=other", nil),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+		httptest.NewRequest(http.MethodGet, "http://other.example.com/", nil),
+	} {
+		r.Header.Set("X-Debug", "1.unsigned")
+		if r.Host == "other.example.com" {
+			r.Header.Set("X-Debug", SignDebugHeader(key, "example.com", time.Now().Add(time.Minute)))
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	assert.Empty(t, sink.entries)
+	assert.Empty(t, sink.events)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Debug", SignDebugHeader(key, r.Host, time.Now().Add(time.Minute)))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?debug=token", nil))
+
+	assert.Len(t, sink.entries, 2)
+	assert.Len(t, sink.events, 2)
+}
+
+func TestHTTPMiddlewareDebugQueryParamNotLogged(t *testing.T) {
+	var out bytes.Buffer
+
+	l, sink := newRecordingLogger()
+	l.SetOutput(&out)
+
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Debug(r.Context(), "Handling")
+	}), WithDebugQueryParam("debug", "s3cr3t-token"))
+
+	r := httptest.NewRequest(http.MethodGet, "/users?page=2&debug=s3cr3t-token", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r.WithContext(WithLogger(r.Context(), l)))
+
+	require.Len(t, sink.events, 1)
+	req, ok := sink.events[0].fields.retrieve(httpRequestKey).(*HTTPRequest)
+	require.True(t, ok)
+	assert.Equal(t, "/users?page=2", req.URL)
+	assert.NotEmpty(t, out.String())
+	assert.NotContains(t, out.String(), "s3cr3t-token")
+}
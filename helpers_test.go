@@ -0,0 +1,63 @@
+package clogger
+
+import (
+	"sync"
+	"testing"
+)
+
+// recordingSink records every log entry and event written to it.
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []*LogEntry
+	events  []*Event
+}
+
+func (s *recordingSink) WriteLogEntry(entry *LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *recordingSink) WriteEvent(event *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	return nil
+}
+
+// newRecordingLogger returns a DefaultLogger writing to the returned recordingSink.
+func newRecordingLogger() (*DefaultLogger, *recordingSink) {
+	sink := &recordingSink{}
+
+	l := NewDefaultLogger()
+	l.SetSinks(sink)
+
+	return l, sink
+}
+
+// setGlobal sets l as the global logger until the end of the test, restoring the previous one afterwards.
+// Prefer WithLogger, unless the global logger itself is under test.
+func setGlobal(t *testing.T, l Logger) {
+	prev := logger()
+	SetGlobal(l)
+	t.Cleanup(func() { SetGlobal(prev) })
+}
+
+// Events returns the events written so far.
+func (s *recordingSink) Events() []*Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Event(nil), s.events...)
+}
+
+// Entries returns the log entries written so far.
+func (s *recordingSink) Entries() []*LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*LogEntry(nil), s.entries...)
+}
@@ -0,0 +1,121 @@
+package clogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	httpRequestKey = "http_request"
+)
+
+// HTTPRequest describes an HTTP request, together with its response, that an Event or a LogEntry refers to.
+// StackdriverEncoder renders it as the httpRequest structured field understood by Cloud Logging:
+// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#HttpRequest
+type HTTPRequest struct {
+	Method       string
+	URL          string
+	Status       int
+	RequestSize  int64
+	ResponseSize int64
+	UserAgent    string
+	RemoteIP     string
+	Referer      string
+	Latency      time.Duration
+	Protocol     string
+}
+
+// NewHTTPRequest fills an HTTPRequest with everything that is known about r before it gets served.
+// Status, ResponseSize and Latency are left for the caller to set once the response is written.
+func NewHTTPRequest(r *http.Request) *HTTPRequest {
+	req := &HTTPRequest{
+		Method:    r.Method,
+		URL:       r.URL.String(),
+		UserAgent: r.UserAgent(),
+		RemoteIP:  r.RemoteAddr,
+		Referer:   r.Referer(),
+		Protocol:  r.Proto,
+	}
+
+	if r.ContentLength > 0 {
+		req.RequestSize = r.ContentLength
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		req.RemoteIP = host
+	}
+
+	return req
+}
+
+// MarshalJSON renders the request in a generic, snake_cased form.
+// It is used by the JSON and Terminal encoders.
+func (r *HTTPRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Method       string `json:"method,omitempty"`
+		URL          string `json:"url,omitempty"`
+		Status       int    `json:"status,omitempty"`
+		RequestSize  int64  `json:"request_size,omitempty"`
+		ResponseSize int64  `json:"response_size,omitempty"`
+		UserAgent    string `json:"user_agent,omitempty"`
+		RemoteIP     string `json:"remote_ip,omitempty"`
+		Referer      string `json:"referer,omitempty"`
+		Latency      string `json:"latency,omitempty"`
+		Protocol     string `json:"protocol,omitempty"`
+	}{
+		Method:       r.Method,
+		URL:          r.URL,
+		Status:       r.Status,
+		RequestSize:  r.RequestSize,
+		ResponseSize: r.ResponseSize,
+		UserAgent:    r.UserAgent,
+		RemoteIP:     r.RemoteIP,
+		Referer:      r.Referer,
+		Latency:      r.Latency.String(),
+		Protocol:     r.Protocol,
+	})
+}
+
+// String returns a short, human friendly summary e.g. "GET /users 200 1.2ms".
+func (r *HTTPRequest) String() string {
+	return fmt.Sprintf("%s %s %d %s", r.Method, r.URL, r.Status, r.Latency)
+}
+
+// stackdriver returns the request in the shape expected by Cloud Logging.
+// Sizes are int64 values, and as such they are encoded as strings; the latency is a
+// google.protobuf.Duration, encoded as seconds with the "s" suffix.
+func (r *HTTPRequest) stackdriver() map[string]interface{} {
+	out := map[string]interface{}{
+		"requestMethod": r.Method,
+		"requestUrl":    r.URL,
+		"latency":       fmt.Sprintf("%.9fs", r.Latency.Seconds()),
+	}
+
+	if r.Status != 0 {
+		out["status"] = r.Status
+	}
+	if r.RequestSize != 0 {
+		out["requestSize"] = strconv.FormatInt(r.RequestSize, 10)
+	}
+	if r.ResponseSize != 0 {
+		out["responseSize"] = strconv.FormatInt(r.ResponseSize, 10)
+	}
+	if r.UserAgent != "" {
+		out["userAgent"] = r.UserAgent
+	}
+	if r.RemoteIP != "" {
+		out["remoteIp"] = r.RemoteIP
+	}
+	if r.Referer != "" {
+		out["referer"] = r.Referer
+	}
+	if r.Protocol != "" {
+		out["protocol"] = r.Protocol
+	}
+
+	return out
+}
@@ -0,0 +1,169 @@
+package clogger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHTTPRequest() *HTTPRequest {
+	return &HTTPRequest{
+		Method:       http.MethodGet,
+		URL:          "https://example.com/users?id=1",
+		Status:       http.StatusOK,
+		RequestSize:  12,
+		ResponseSize: 345,
+		UserAgent:    "clogger-test",
+		RemoteIP:     "10.0.0.1",
+		Latency:      1500 * time.Millisecond,
+		Protocol:     "HTTP/1.1",
+	}
+}
+
+func TestNewHTTPRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "https://example.com/users", nil)
+	r.RemoteAddr = "192.168.1.10:53211"
+	r.Header.Set("User-Agent", "clogger-test")
+
+	req := NewHTTPRequest(r)
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "https://example.com/users", req.URL)
+	assert.Equal(t, "192.168.1.10", req.RemoteIP)
+	assert.Equal(t, "clogger-test", req.UserAgent)
+	assert.Equal(t, "HTTP/1.1", req.Protocol)
+}
+
+func TestHTTPRequestStackdriver(t *testing.T) {
+	entry := newLogEntry()
+	entry.WithHTTPRequest(testHTTPRequest())
+	entry.message = "served"
+
+	b, err := (&StackdriverEncoder{}).EncodeLogEntry(entry)
+	require.NoError(t, err)
+
+	out := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(b, &out))
+	require.Contains(t, out, "httpRequest")
+	assert.NotContains(t, out, httpRequestKey)
+
+	req := out["httpRequest"].(map[string]interface{})
+	assert.Equal(t, "GET", req["requestMethod"])
+	assert.Equal(t, "https://example.com/users?id=1", req["requestUrl"])
+	assert.Equal(t, float64(200), req["status"])
+	assert.Equal(t, "12", req["requestSize"])
+	assert.Equal(t, "345", req["responseSize"])
+	assert.Equal(t, "10.0.0.1", req["remoteIp"])
+	assert.Equal(t, "1.500000000s", req["latency"])
+}
+
+func TestHTTPRequestEventEncoders(t *testing.T) {
+	setGlobal(t, NewDefaultLogger())
+
+	_, ev := NewEvent(context.Background(), "served")
+	ev.SetHTTPRequest(testHTTPRequest())
+	ev.Set("key", "value")
+
+	b, err := (&StackdriverEncoder{}).EncodeEvent(ev)
+	require.NoError(t, err)
+	out := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Contains(t, out, "httpRequest")
+	assert.Equal(t, map[string]interface{}{"key": "value"}, out["fields"])
+
+	b, err = (&JSONEncoder{}).EncodeEvent(ev)
+	require.NoError(t, err)
+	out = make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(b, &out))
+	req := out[httpRequestKey].(map[string]interface{})
+	assert.Equal(t, "GET", req["method"])
+	assert.Equal(t, "1.5s", req["latency"])
+
+	b, err = (&TerminalEncoder{}).EncodeEvent(ev)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "served [GET https://example.com/users?id=1 200 1.5s]")
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Set(r.Context(), "handler_key", "handler_value")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("unavailable"))
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	require.Len(t, sink.Events(), 1)
+	streamed := sink.Events()[0]
+	assert.Equal(t, "GET /health", streamed.message)
+	assert.Equal(t, SeverityError, streamed.severity)
+	assert.Equal(t, "handler_value", streamed.fields.retrieve("handler_key"))
+
+	req, ok := streamed.fields.retrieve(httpRequestKey).(*HTTPRequest)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, req.Status)
+	assert.Equal(t, int64(len("unavailable")), req.ResponseSize)
+}
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	l, sink := newRecordingLogger()
+
+	srv := httptest.NewUnstartedServer(HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.ErrNotSupported, w.(http.Pusher).Push("/style.css", nil), "HTTP/1.1 does not push")
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+		_ = rw.Flush()
+	})))
+	srv.Config.BaseContext = func(net.Listener) context.Context {
+		return WithLogger(context.Background(), l)
+	}
+	srv.Start()
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n"))
+	require.NoError(t, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.events) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	req, ok := sink.events[0].fields.retrieve(httpRequestKey).(*HTTPRequest)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusSwitchingProtocols, req.Status)
+}
+
+func TestResponseRecorderUnwrap(t *testing.T) {
+	w := httptest.NewRecorder()
+	rr := &responseRecorder{ResponseWriter: w}
+	assert.Equal(t, w, rr.Unwrap())
+
+	_, _, err := rr.Hijack()
+	assert.Equal(t, http.ErrNotSupported, err)
+}
@@ -0,0 +1,279 @@
+package clogger
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCOption func(c *grpcConfig)
+
+type grpcConfig struct {
+	propagator propagation.TextMapPropagator
+	baggage    *BaggageLabels
+}
+
+// WithGRPCPropagator sets the propagator used to carry the trace context
+// through the call's metadata. Defaults to the globally registered one.
+func WithGRPCPropagator(p propagation.TextMapPropagator) GRPCOption {
+	return func(c *grpcConfig) {
+		c.propagator = p
+	}
+}
+
+// WithGRPCBaggage sends the allowed labels of the event found in the call's context downstream, through
+// the W3C baggage metadata entry, and restores the allowed labels received through it.
+// Without it, no labels are sent nor restored.
+func WithGRPCBaggage(b BaggageLabels) GRPCOption {
+	return func(c *grpcConfig) {
+		c.baggage = &b
+	}
+}
+
+func newGRPCConfig(opts []GRPCOption) *grpcConfig {
+	c := &grpcConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *grpcConfig) textMapPropagator() propagation.TextMapPropagator {
+	if c.propagator != nil {
+		return c.propagator
+	}
+
+	return otel.GetTextMapPropagator()
+}
+
+// UnaryServerInterceptor wraps every unary call in an Event, passed down through the handler's context.
+// The trace context and the caller's labels, see WithGRPCBaggage, are restored from the incoming metadata.
+func UnaryServerInterceptor(opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	c := newGRPCConfig(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, ev := c.newServerEvent(ctx, info.FullMethod)
+		defer ev.End()
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		endGRPCEvent(ev, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor wraps every streaming call in an Event, passed down through the stream's context.
+// The trace context and the caller's labels, see WithGRPCBaggage, are restored from the incoming metadata.
+func StreamServerInterceptor(opts ...GRPCOption) grpc.StreamServerInterceptor {
+	c := newGRPCConfig(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, ev := c.newServerEvent(ss.Context(), info.FullMethod)
+		defer ev.End()
+
+		start := time.Now()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		endGRPCEvent(ev, start, err)
+
+		return err
+	}
+}
+
+// UnaryClientInterceptor logs every outgoing unary call; if the call's context holds an Event,
+// the log entry becomes one of its child logs. The trace context and the event's labels,
+// see WithGRPCBaggage, are propagated downstream through the outgoing metadata.
+func UnaryClientInterceptor(opts ...GRPCOption) grpc.UnaryClientInterceptor {
+	c := newGRPCConfig(opts)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(c.outgoingContext(ctx), method, req, reply, cc, callOpts...)
+		logGRPCClientCall(ctx, method, cc.Target(), start, err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor logs every outgoing streaming call, once the stream is over i.e. once receiving
+// from it returns an error (io.EOF included) or, without server streaming, once the response is received.
+// The trace context and the event's labels, see WithGRPCBaggage, are propagated downstream
+// through the outgoing metadata.
+func StreamClientInterceptor(opts ...GRPCOption) grpc.StreamClientInterceptor {
+	c := newGRPCConfig(opts)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(c.outgoingContext(ctx), desc, cc, method, callOpts...)
+		if err != nil {
+			logGRPCClientCall(ctx, method, cc.Target(), start, err)
+			return nil, err
+		}
+
+		return &clientStream{
+			ClientStream:  cs,
+			ctx:           ctx,
+			method:        method,
+			target:        cc.Target(),
+			start:         start,
+			serverStreams: desc.ServerStreams,
+		}, nil
+	}
+}
+
+func (c *grpcConfig) newServerEvent(ctx context.Context, method string) (context.Context, *Event) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = c.textMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx = contextWithBaggage(ctx, metadataCarrier(md).Get(baggageHeader))
+
+	ctx, ev := NewEvent(ctx, method)
+	if c.baggage != nil {
+		for key, value := range c.baggage.labels(metadataCarrier(md).Get(baggageHeader)) {
+			ev.SetLabel(key, value)
+		}
+	}
+
+	ev.Set("grpc_method", method)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ev.Set("peer", p.Addr.String())
+	}
+
+	return ctx, ev
+}
+
+// outgoingContext injects the trace context and the allowed labels of the event found in ctx, if any,
+// into the outgoing metadata.
+func (c *grpcConfig) outgoingContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+
+	c.textMapPropagator().Inject(ctx, metadataCarrier(md))
+	if c.baggage == nil {
+		return metadata.NewOutgoingContext(ctx, md)
+	}
+
+	// Escaped by the header, non-ASCII values included, as metadata values must be printable ASCII
+	if b := c.baggage.header(ctx, metadataCarrier(md).Get(baggageHeader)); b != "" {
+		md.Set(baggageHeader, b)
+	}
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func endGRPCEvent(ev *Event, start time.Time, err error) {
+	code := status.Code(err)
+	ev.Set("grpc_code", code.String())
+	ev.Set("latency", time.Since(start).String())
+	if err != nil {
+		ev.SetOnErr("error", err.Error())
+	}
+
+	ev.raiseSeverity(severityFromGRPCCode(code))
+}
+
+func logGRPCClientCall(ctx context.Context, method, target string, start time.Time, err error) {
+	code := status.Code(err)
+
+	entry := newLogEntry()
+	entry.With("grpc_method", method).
+		With("grpc_code", code.String()).
+		With("peer", target).
+		With("latency", time.Since(start).String())
+	if err != nil {
+		entry.With("error", err.Error())
+	}
+
+	entry.log(ctx, severityFromGRPCCode(code), method).dispatch()
+}
+
+// severityFromGRPCCode maps the status of a finished call to a Severity.
+// Codes caused by the caller are reported as warnings, the ones caused by the server as errors.
+func severityFromGRPCCode(code codes.Code) Severity {
+	switch code {
+	case codes.OK:
+		return SeverityInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return SeverityWarn
+	default:
+		return SeverityError
+	}
+}
+
+// serverStream overrides the stream's context with the one holding the Event.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// clientStream logs the call once the stream is over.
+type clientStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	method string
+	target string
+	start  time.Time
+	done   bool
+
+	// Without server streaming, e.g. client streaming calls, the first message received is the last one.
+	serverStreams bool
+}
+
+func (cs *clientStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+	if cs.done || (err == nil && cs.serverStreams) {
+		return err
+	}
+
+	cs.done = true
+	if err == io.EOF {
+		logGRPCClientCall(cs.ctx, cs.method, cs.target, cs.start, nil)
+	} else {
+		logGRPCClientCall(cs.ctx, cs.method, cs.target, cs.start, err)
+	}
+
+	return err
+}
+
+// metadataCarrier adapts metadata.MD to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (mc metadataCarrier) Get(key string) string {
+	values := metadata.MD(mc).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (mc metadataCarrier) Set(key string, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for k := range mc {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,220 @@
+package clogger
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newBufconnHealthClient serves the standard health service over an in-memory connection,
+// with both the client and the server side intercepted, sharing the given options.
+func newBufconnHealthClient(t *testing.T, opts ...GRPCOption) healthpb.HealthClient {
+	t.Helper()
+
+	opts = append([]GRPCOption{WithGRPCPropagator(propagation.TraceContext{})}, opts...)
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(opts...)),
+		grpc.StreamInterceptor(StreamServerInterceptor(opts...)),
+	)
+	hs := health.NewServer()
+	hs.SetServingStatus("billing", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(opts...)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(opts...)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func tracedContext() context.Context {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestGRPCUnaryInterceptors(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	client := newBufconnHealthClient(t, WithGRPCBaggage(BaggageLabels{Keys: []string{"tenant_id", "user_id"}, MaxBytes: 16}))
+
+	ctx, ev := NewEvent(tracedContext(), "Calling billing")
+	ev.SetLabel("tenant_id", "acme")
+	ev.SetLabel("user_id", strings.Repeat("1", 32))
+	ev.SetLabel("session", "not allowed")
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "billing"})
+	require.NoError(t, err)
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	ev.End()
+
+	// Client side: one child log per call
+	require.Len(t, ev.logs, 2)
+	assert.Equal(t, "/grpc.health.v1.Health/Check", ev.logs[0].fields.retrieve("grpc_method"))
+	assert.Equal(t, codes.OK.String(), ev.logs[0].fields.retrieve("grpc_code"))
+	assert.Equal(t, SeverityInfo, ev.logs[0].severity)
+	assert.Equal(t, codes.NotFound.String(), ev.logs[1].fields.retrieve("grpc_code"))
+	assert.Equal(t, SeverityWarn, ev.logs[1].severity)
+
+	// Server side: one event per call, with the caller's allowed labels restored
+	var server []*Event
+	for _, e := range sink.Events() {
+		if e != ev {
+			server = append(server, e)
+		}
+	}
+	require.Len(t, server, 2)
+	for _, e := range server {
+		assert.Equal(t, "/grpc.health.v1.Health/Check", e.message)
+		assert.Equal(t, "acme", e.labels.retrieve("tenant_id"))
+		assert.Nil(t, e.labels.retrieve("user_id"), "Labels over MaxBytes should not be sent")
+		assert.Nil(t, e.labels.retrieve("session"), "Labels not allowed should not be sent")
+		assert.NotEmpty(t, e.fields.retrieve("peer"))
+	}
+	assert.Equal(t, SeverityInfo, server[0].severity)
+	assert.Equal(t, SeverityWarn, server[1].severity)
+	assert.Equal(t, codes.NotFound.String(), server[1].fields.retrieve("grpc_code"))
+}
+
+func TestGRPCStreamInterceptors(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	client := newBufconnHealthClient(t)
+
+	ctx, ev := NewEvent(tracedContext(), "Watching billing")
+	callCtx, cancel := context.WithCancel(ctx)
+
+	stream, err := client.Watch(callCtx, &healthpb.HealthCheckRequest{Service: "billing"})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	cancel()
+	_, err = stream.Recv()
+	require.Equal(t, codes.Canceled, status.Code(err))
+	ev.End()
+
+	require.Len(t, ev.logs, 1)
+	assert.Equal(t, "/grpc.health.v1.Health/Watch", ev.logs[0].fields.retrieve("grpc_method"))
+	assert.Equal(t, codes.Canceled.String(), ev.logs[0].fields.retrieve("grpc_code"))
+
+	// The server side event ends asynchronously, once the cancellation reaches it.
+	require.Eventually(t, func() bool {
+		for _, e := range sink.Events() {
+			if e.message == "/grpc.health.v1.Health/Watch" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSeverityFromGRPCCode(t *testing.T) {
+	assert.Equal(t, SeverityInfo, severityFromGRPCCode(codes.OK))
+	assert.Equal(t, SeverityWarn, severityFromGRPCCode(codes.InvalidArgument))
+	assert.Equal(t, SeverityWarn, severityFromGRPCCode(codes.Unauthenticated))
+	assert.Equal(t, SeverityError, severityFromGRPCCode(codes.Internal))
+	assert.Equal(t, SeverityError, severityFromGRPCCode(codes.DeadlineExceeded))
+	assert.Equal(t, SeverityError, severityFromGRPCCode(codes.Unknown))
+}
+
+func TestGRPCLabelsReceived(t *testing.T) {
+	c := newGRPCConfig([]GRPCOption{WithGRPCBaggage(BaggageLabels{Keys: []string{"tenantID", "city"}, MaxBytes: 32})})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		baggageHeader, "tenantID=acme,session=not%20allowed,city=Z%C3%BCrich",
+	))
+	_, ev := c.newServerEvent(ctx, "/billing.Billing/Charge")
+	assert.Equal(t, "acme", ev.labels.retrieve("tenantID"))
+	assert.Equal(t, "Zürich", ev.labels.retrieve("city"))
+	assert.Equal(t, 2, ev.labels.len())
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(baggageHeader, "tenantID="+strings.Repeat("1", 32)))
+	_, ev = c.newServerEvent(ctx, "/billing.Billing/Charge")
+	assert.Zero(t, ev.labels.len(), "Labels over MaxBytes should not be restored")
+
+	_, ev = newGRPCConfig(nil).newServerEvent(metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(baggageHeader, "tenantID=acme")), "/billing.Billing/Charge")
+	assert.Zero(t, ev.labels.len(), "Labels should only be restored through WithGRPCBaggage")
+}
+
+func TestGRPCLabelsSentEscaped(t *testing.T) {
+	c := newGRPCConfig([]GRPCOption{WithGRPCBaggage(BaggageLabels{Keys: []string{"city"}})})
+
+	ctx, ev := NewDefaultLogger().NewEvent(context.Background(), "Outbound call")
+	ev.SetLabel("city", "Zürich")
+
+	out, ok := metadata.FromOutgoingContext(c.outgoingContext(ctx))
+	require.True(t, ok)
+	assert.Equal(t, []string{"city=Z%C3%BCrich"}, out.Get(baggageHeader))
+	assert.Len(t, out, 1, "Labels should only travel through the baggage")
+}
+
+// fakeClientStream receives a single message, as client streaming calls do.
+type fakeClientStream struct {
+	grpc.ClientStream
+}
+
+func (fakeClientStream) RecvMsg(m interface{}) error {
+	return nil
+}
+
+func TestGRPCClientStreamingInterceptor(t *testing.T) {
+	l, sink := newRecordingLogger()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return fakeClientStream{}, nil
+	}
+
+	ctx := WithLogger(context.Background(), l)
+	desc := &grpc.StreamDesc{ClientStreams: true}
+	cs, err := StreamClientInterceptor()(ctx, desc, conn, "/billing.Billing/Upload", streamer)
+	require.NoError(t, err)
+
+	// As done by CloseAndRecv, once the messages are sent
+	require.NoError(t, cs.RecvMsg(nil))
+
+	entries := sink.Entries()
+	require.Len(t, entries, 1, "A successful client streaming call should be logged")
+	assert.Equal(t, "/billing.Billing/Upload", entries[0].fields.retrieve("grpc_method"))
+	assert.Equal(t, codes.OK.String(), entries[0].fields.retrieve("grpc_code"))
+	assert.Equal(t, SeverityInfo, entries[0].severity)
+}
@@ -0,0 +1,281 @@
+package clogger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	truncatedFieldsKey  = "truncated_fields"
+	continuationIDKey   = "continuation_id"
+	continuationPartKey = "continuation_part"
+
+	// Leaves room for the "…truncated N bytes" marker.
+	truncationMarkerBytes = 32
+)
+
+// Limits bounds the size of the log entries and events output by DefaultLogger,
+// e.g. to stay under Cloud Logging's 256 KB limit per entry. Zero values mean no limit.
+//
+// Whatever gets truncated ends with a "…truncated N bytes" marker and its key is listed
+// under the "truncated_fields" field ("message" for the message).
+type Limits struct {
+	// MaxMessageBytes limits the length of the message.
+	MaxMessageBytes int
+
+	// MaxFieldBytes limits the size of every field value, JSON encoded unless it's a string.
+	MaxFieldBytes int
+
+	// MaxFields limits the number of fields, per collection for events. Fields are kept in alphabetical order.
+	MaxFields int
+
+	// MaxEntryBytes limits the size of the encoded output. The largest fields get truncated first, then the message.
+	MaxEntryBytes int
+
+	// SplitMessages splits the log entries whose message is over MaxMessageBytes into continuation entries
+	// instead of truncating it. They share the same "continuation_id" and are numbered by "continuation_part", e.g. "2/3".
+	SplitMessages bool
+}
+
+// limitLogEntry applies the message and field limits to the log entry, in place.
+// It returns the entries to be output: the entry itself followed by its continuation entries, if any.
+func (lim *Limits) limitLogEntry(entry *LogEntry) []*LogEntry {
+	var truncated []string
+	truncated = append(truncated, lim.limitFields(entry.fields)...)
+
+	parts := []*LogEntry{entry}
+	if lim.MaxMessageBytes > 0 && len(entry.message) > lim.MaxMessageBytes {
+		if lim.SplitMessages {
+			parts = lim.split(entry)
+		} else {
+			entry.message = truncateString(entry.message, lim.MaxMessageBytes)
+			truncated = append(truncated, "message")
+		}
+	}
+
+	markTruncated(entry.fields, truncated...)
+
+	return parts
+}
+
+// limitEvent applies the message and field limits to the event, in place. Event messages are never split.
+func (lim *Limits) limitEvent(event *Event) {
+	var truncated []string
+	truncated = append(truncated, lim.limitFields(event.fields)...)
+	truncated = append(truncated, lim.limitFields(event.errFields)...)
+	truncated = append(truncated, lim.limitFields(event.labels)...)
+
+	if lim.MaxMessageBytes > 0 && len(event.message) > lim.MaxMessageBytes {
+		event.message = truncateString(event.message, lim.MaxMessageBytes)
+		truncated = append(truncated, "message")
+	}
+
+	markTruncated(event.fields, truncated...)
+}
+
+// limitFields drops the fields over MaxFields and truncates the values over MaxFieldBytes,
+// returning the keys of the affected fields.
+func (lim *Limits) limitFields(fc *fieldCollection) []string {
+	if lim.MaxFields <= 0 && lim.MaxFieldBytes <= 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, fc.len())
+	for _, f := range fc.fields() {
+		keys = append(keys, f.key)
+	}
+	sort.Strings(keys)
+
+	dropped := make(map[string]bool)
+	if lim.MaxFields > 0 && len(keys) > lim.MaxFields {
+		for _, k := range keys[lim.MaxFields:] {
+			dropped[k] = true
+		}
+	}
+
+	var truncated []string
+	fc.rewrite(func(key string, value interface{}) (interface{}, bool) {
+		if dropped[key] {
+			truncated = append(truncated, key)
+			return nil, false
+		}
+
+		if lim.MaxFieldBytes > 0 {
+			if s := valueString(value); len(s) > lim.MaxFieldBytes {
+				truncated = append(truncated, key)
+				return truncateString(s, lim.MaxFieldBytes), true
+			}
+		}
+
+		return value, true
+	})
+
+	return truncated
+}
+
+// split splits the message of the entry into parts of MaxMessageBytes, returning the continuation entries
+// after the entry itself. They carry the entry's trace fields, besides the continuation ones.
+func (lim *Limits) split(entry *LogEntry) []*LogEntry {
+	chunks := splitString(entry.message, lim.MaxMessageBytes)
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	continuationID := hex.EncodeToString(id)
+
+	parts := make([]*LogEntry, 0, len(chunks))
+	for i, chunk := range chunks {
+		part := entry
+		if i > 0 {
+			part = newLogEntry()
+			part.timestamp = entry.timestamp
+			part.severity = entry.severity
+			part.template = entry.template
+			for _, key := range []string{otelTraceID, otelSpanID, otelSampled, opencensusTraceID, opencensusSpanID, opencensusSampled} {
+				if v := entry.fields.retrieve(key); v != nil {
+					part.fields.add(key, v)
+				}
+			}
+		}
+
+		part.message = chunk
+		part.fields.add(continuationIDKey, continuationID)
+		part.fields.add(continuationPartKey, fmt.Sprintf("%d/%d", i+1, len(chunks)))
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+// encode calls encode until its output fits MaxEntryBytes, truncating the largest fields first, then the message.
+func (lim *Limits) encode(encode func() ([]byte, error), message *string, fcs ...*fieldCollection) ([]byte, error) {
+	out, err := encode()
+	for err == nil && lim.MaxEntryBytes > 0 && len(out) > lim.MaxEntryBytes {
+		if !shrink(len(out)-lim.MaxEntryBytes, message, fcs) {
+			break
+		}
+
+		out, err = encode()
+	}
+
+	return out, err
+}
+
+// shrink truncates the largest field by excess bytes, or the message if no field is large enough.
+// It reports whether anything could be truncated.
+func shrink(excess int, message *string, fcs []*fieldCollection) bool {
+	var (
+		largest     *fieldCollection
+		largestKey  string
+		largestSize int
+	)
+	for _, fc := range fcs {
+		for _, f := range fc.fields() {
+			if f.key == truncatedFieldsKey {
+				continue
+			}
+
+			if size := len(valueString(f.value)); size > largestSize {
+				largest, largestKey, largestSize = fc, f.key, size
+			}
+		}
+	}
+
+	if largest != nil && largestSize > truncationMarkerBytes {
+		largest.update(largestKey, func(value interface{}) interface{} {
+			return truncateString(valueString(value), largestSize-excess-truncationMarkerBytes)
+		})
+		markTruncated(fcs[0], largestKey)
+
+		return true
+	}
+
+	if len(*message) > truncationMarkerBytes {
+		*message = truncateString(*message, len(*message)-excess-truncationMarkerBytes)
+		markTruncated(fcs[0], "message")
+
+		return true
+	}
+
+	return false
+}
+
+// markTruncated adds keys to the "truncated_fields" field of fc.
+func markTruncated(fc *fieldCollection, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	fc.update(truncatedFieldsKey, func(value interface{}) interface{} {
+		list, _ := value.([]string)
+		for _, k := range keys {
+			found := false
+			for _, existing := range list {
+				found = found || existing == k
+			}
+
+			if !found {
+				list = append(list, k)
+			}
+		}
+		sort.Strings(list)
+
+		return list
+	})
+}
+
+// valueString returns the value as a string, JSON encoded unless it already is one.
+func valueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(b)
+}
+
+// truncateString cuts s to max bytes, on a rune boundary, followed by a "…truncated N bytes" marker.
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	cut := runeBoundary(s, max)
+	return s[:cut] + fmt.Sprintf("…truncated %d bytes", len(s)-cut)
+}
+
+// splitString splits s into chunks of up to max bytes, on rune boundaries.
+func splitString(s string, max int) []string {
+	var chunks []string
+	for len(s) > max {
+		cut := runeBoundary(s, max)
+		if cut == 0 {
+			_, cut = utf8.DecodeRuneInString(s)
+		}
+
+		chunks = append(chunks, s[:cut])
+		s = s[cut:]
+	}
+
+	return append(chunks, s)
+}
+
+// runeBoundary returns the largest index <= max which doesn't split a rune of s.
+func runeBoundary(s string, max int) int {
+	if max <= 0 {
+		return 0
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+
+	return max
+}
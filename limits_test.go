@@ -0,0 +1,90 @@
+package clogger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateString(t *testing.T) {
+	assert.Equal(t, "short", truncateString("short", 10))
+	assert.Equal(t, "abc…truncated 3 bytes", truncateString("abcdef", 3))
+	// Never splits a rune
+	assert.Equal(t, "a…truncated 4 bytes", truncateString("aéé", 2))
+
+	assert.Equal(t, []string{"ab", "cd", "e"}, splitString("abcde", 2))
+	assert.Equal(t, []string{"é", "é"}, splitString("éé", 3))
+}
+
+func TestLimitsLogEntry(t *testing.T) {
+	lim := &Limits{MaxMessageBytes: 5, MaxFieldBytes: 4, MaxFields: 2}
+
+	entry := newLogEntry()
+	entry.message = "Too long message"
+	entry.fields.add("a", "abcdefgh")
+	entry.fields.add("b", []int{1, 2, 3})
+	entry.fields.add("c", "dropped")
+
+	parts := lim.limitLogEntry(entry)
+	require.Len(t, parts, 1)
+	assert.Equal(t, "Too l…truncated 11 bytes", entry.message)
+	assert.Equal(t, "abcd…truncated 4 bytes", entry.fields.retrieve("a"))
+	assert.Equal(t, "[1,2…truncated 3 bytes", entry.fields.retrieve("b"))
+	assert.Nil(t, entry.fields.retrieve("c"))
+	assert.Equal(t, []string{"a", "b", "c", "message"}, entry.fields.retrieve(truncatedFieldsKey))
+}
+
+func TestLimitsSplitMessages(t *testing.T) {
+	lim := &Limits{MaxMessageBytes: 4, SplitMessages: true}
+
+	entry := newLogEntry()
+	entry.severity = SeverityWarn
+	entry.message = "0123456789"
+	entry.fields.add("key", "value")
+	entry.fields.add(otelTraceID, "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	parts := lim.limitLogEntry(entry)
+	require.Len(t, parts, 3)
+
+	id := entry.fields.retrieve(continuationIDKey)
+	require.NotEmpty(t, id)
+	for i, want := range []string{"0123", "4567", "89"} {
+		assert.Equal(t, want, parts[i].message)
+		assert.Equal(t, SeverityWarn, parts[i].severity)
+		assert.Equal(t, id, parts[i].fields.retrieve(continuationIDKey))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", parts[i].fields.retrieve(otelTraceID))
+	}
+	assert.Equal(t, "2/3", parts[1].fields.retrieve(continuationPartKey))
+	assert.Nil(t, parts[1].fields.retrieve("key"))
+}
+
+func TestDefaultLoggerMaxEntryBytes(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetEncoder(&StackdriverEncoder{})
+	l.SetLimits(Limits{MaxEntryBytes: 1024})
+	setGlobal(t, l)
+
+	ctx, ev := NewEvent(context.Background(), "Upload")
+	ev.Set("body", strings.Repeat("x", 4096))
+	ev.Set("small", "kept")
+	With("body", strings.Repeat("y", 2048)).With("other", strings.Repeat("z", 1024)).Info(ctx, "Received")
+	ev.End()
+
+	require.Len(t, sink.entries, 1)
+	entry := sink.entries[0]
+	b, err := l.enc.EncodeLogEntry(entry)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(b), 1024)
+	assert.Contains(t, entry.fields.retrieve("body"), "…truncated")
+	assert.Contains(t, entry.fields.retrieve(truncatedFieldsKey), "body")
+
+	require.Len(t, sink.events, 1)
+	b, err = l.enc.EncodeEvent(sink.events[0])
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(b), 1024)
+	assert.Equal(t, "kept", sink.events[0].fields.retrieve("small"))
+	assert.Equal(t, []string{"body"}, sink.events[0].fields.retrieve(truncatedFieldsKey))
+}
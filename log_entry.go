@@ -9,6 +9,13 @@ import (
 
 type Loggable interface {
 	With(key string, value interface{}) Loggable
+	WithHTTPRequest(req *HTTPRequest) Loggable
+
+	Log(ctx context.Context, sev Severity, message string)
+	Logf(ctx context.Context, sev Severity, message string, args ...interface{})
+
+	Trace(ctx context.Context, message string)
+	Tracef(ctx context.Context, message string, args ...interface{})
 
 	Debug(ctx context.Context, message string)
 	Debugf(ctx context.Context, message string, args ...interface{})
@@ -16,6 +23,9 @@ type Loggable interface {
 	Info(ctx context.Context, message string)
 	Infof(ctx context.Context, message string, args ...interface{})
 
+	Notice(ctx context.Context, message string)
+	Noticef(ctx context.Context, message string, args ...interface{})
+
 	Warn(ctx context.Context, message string)
 	Warnf(ctx context.Context, message string, args ...interface{})
 
@@ -24,6 +34,12 @@ type Loggable interface {
 
 	Fatal(ctx context.Context, message string)
 	Fatalf(ctx context.Context, message string, args ...interface{})
+
+	Alert(ctx context.Context, message string)
+	Alertf(ctx context.Context, message string, args ...interface{})
+
+	Emergency(ctx context.Context, message string)
+	Emergencyf(ctx context.Context, message string, args ...interface{})
 }
 
 // LogEntry defines the structure of a single, unitary log entry.
@@ -31,6 +47,10 @@ type LogEntry struct {
 	message   string
 	timestamp time.Time
 
+	// The format the message was built from, if any,
+	// otherwise the message itself.
+	template string
+
 	// Set by the caller indirectly through
 	// one of the methods Debug/Info/Warn etc.
 	severity Severity
@@ -42,6 +62,28 @@ type LogEntry struct {
 	// Keep track if this log entry
 	// is part of an Event's lifecycle
 	eventful bool
+
+	// Bypasses the minimum severity and sampling, see ContextWithForcedDebug.
+	forced bool
+
+	// The logger the entry goes through. Unless bound beforehand, e.g. by DefaultLogger.With,
+	// it is resolved from the context it gets logged with, see WithLogger.
+	logger Logger
+
+	// The name of the NamedLogger the entry went through, if any.
+	name string
+
+	// The "file:line" the entry was logged from, captured only if its logger rate limits its severity.
+	// Child logs are streamed later on, from Event.End.
+	caller string
+
+	// The sampling key of the event the entry belongs to, if any, and whether its trace is sampled, see Event.
+	sampleKey    uint64
+	traceSampled bool
+
+	// Functions to be called once the entry passed the logger's level, sampling and rate limits,
+	// then got redacted, right before being written, e.g. to mirror it onto a span.
+	writeFns []func()
 }
 
 func newLogEntry() *LogEntry {
@@ -54,36 +96,58 @@ func newLogEntry() *LogEntry {
 	}
 }
 
+// boundLogEntry returns a log entry going through l, whatever the context it gets logged with.
+func boundLogEntry(l Logger) *LogEntry {
+	e := newLogEntry()
+	e.logger = l
+
+	return e
+}
+
 func (e *LogEntry) log(ctx context.Context, sev Severity, msg string) *LogEntry {
 	e.message = msg
 	e.severity = sev
+	if e.template == "" {
+		e.template = msg
+	}
 
 	// Guard against nil contexts
 	if ctx == nil {
 		ctx = context.TODO()
 	}
 
+	if e.logger == nil {
+		e.logger = loggerFrom(ctx)
+	}
+	if r, ok := e.logger.(interface{ rateLimited(Severity) bool }); ok && r.rateLimited(sev) {
+		e.caller = callerLocation()
+	}
+	e.forced = DebugForced(ctx)
+
 	// Apply any custom decorators onto this LogEntry
 	e.apply(ctx)
 
 	// Check whether this is part of a greater event
 	if event, ok := ctx.Value(eventKey).(*Event); ok {
-		// Mark this log entry as such
-		e.eventful = true
-
-		// Add this log entry as a child log,
-		// attached to the found event.
-		event.logs = append(event.logs, e)
-
-		// If the log's severity is greater than the
-		if sev > event.severity {
-			event.severity = sev
-		}
+		// Add this log entry as a child log, attached to the found event, and mark it as such.
+		// If the log's severity is greater than the event's, it gets raised.
+		// Once the event has ended, the log entry gets output on its own.
+		e.eventful = event.addLog(e)
 	}
 
 	return e
 }
 
+func (e *LogEntry) logf(ctx context.Context, sev Severity, format string, args ...interface{}) *LogEntry {
+	e.template = format
+	return e.log(ctx, sev, fmt.Sprintf(format, redactedArgs(args)...))
+}
+
+// onWrite registers fn to be called once the entry is about to be written, see writeFns.
+func (e *LogEntry) onWrite(fn func()) {
+	e.writeFns = append(e.writeFns, fn)
+}
+
 // dispatch will output the LogEntry or return early
 // if it's part of a bigger event.
 func (e *LogEntry) dispatch() {
@@ -94,12 +158,15 @@ func (e *LogEntry) dispatch() {
 
 	// Otherwise, it's an isolated LogEntry;
 	// output directly
-	logger().StreamLogEntry(e)
+	e.logger.StreamLogEntry(e)
 }
 
 func (e *LogEntry) apply(ctx context.Context) {
-	for _, opt := range logger().LogEntryOptions() {
-		opt(ctx, e)
+	for _, opt := range e.logger.LogEntryOptions() {
+		func() {
+			defer recoverOption(e.logger, "log entry")
+			opt(ctx, e)
+		}()
 	}
 }
 
@@ -113,6 +180,31 @@ func (e *LogEntry) With(key string, value interface{}) Loggable {
 	return e
 }
 
+// WithHTTPRequest ...
+func (e *LogEntry) WithHTTPRequest(req *HTTPRequest) Loggable {
+	return e.With(httpRequestKey, req)
+}
+
+// Log logs the message with the given severity, custom ones included.
+func (e *LogEntry) Log(ctx context.Context, sev Severity, message string) {
+	e.log(ctx, sev, message).dispatch()
+}
+
+// Logf ...
+func (e *LogEntry) Logf(ctx context.Context, sev Severity, message string, args ...interface{}) {
+	e.logf(ctx, sev, message, args...).dispatch()
+}
+
+// Trace ...
+func (e *LogEntry) Trace(ctx context.Context, message string) {
+	e.log(ctx, SeverityTrace, message).dispatch()
+}
+
+// Tracef ...
+func (e *LogEntry) Tracef(ctx context.Context, message string, args ...interface{}) {
+	e.logf(ctx, SeverityTrace, message, args...).dispatch()
+}
+
 // Debug ...
 func (e *LogEntry) Debug(ctx context.Context, message string) {
 	e.log(ctx, SeverityDebug, message).dispatch()
@@ -120,7 +212,7 @@ func (e *LogEntry) Debug(ctx context.Context, message string) {
 
 // Debugf ...
 func (e *LogEntry) Debugf(ctx context.Context, message string, args ...interface{}) {
-	e.log(ctx, SeverityDebug, fmt.Sprintf(message, args...)).dispatch()
+	e.logf(ctx, SeverityDebug, message, args...).dispatch()
 }
 
 // Info ...
@@ -130,7 +222,17 @@ func (e *LogEntry) Info(ctx context.Context, message string) {
 
 // Infof ...
 func (e *LogEntry) Infof(ctx context.Context, message string, args ...interface{}) {
-	e.log(ctx, SeverityInfo, fmt.Sprintf(message, args...)).dispatch()
+	e.logf(ctx, SeverityInfo, message, args...).dispatch()
+}
+
+// Notice ...
+func (e *LogEntry) Notice(ctx context.Context, message string) {
+	e.log(ctx, SeverityNotice, message).dispatch()
+}
+
+// Noticef ...
+func (e *LogEntry) Noticef(ctx context.Context, message string, args ...interface{}) {
+	e.logf(ctx, SeverityNotice, message, args...).dispatch()
 }
 
 // Warn ...
@@ -140,7 +242,7 @@ func (e *LogEntry) Warn(ctx context.Context, message string) {
 
 // Warnf ...
 func (e *LogEntry) Warnf(ctx context.Context, message string, args ...interface{}) {
-	e.log(ctx, SeverityWarn, fmt.Sprintf(message, args...)).dispatch()
+	e.logf(ctx, SeverityWarn, message, args...).dispatch()
 }
 
 // Error ...
@@ -150,7 +252,7 @@ func (e *LogEntry) Error(ctx context.Context, message string) {
 
 // Errorf ...
 func (e *LogEntry) Errorf(ctx context.Context, message string, args ...interface{}) {
-	e.log(ctx, SeverityError, fmt.Sprintf(message, args...)).dispatch()
+	e.logf(ctx, SeverityError, message, args...).dispatch()
 }
 
 // Fatal ...
@@ -161,6 +263,26 @@ func (e *LogEntry) Fatal(ctx context.Context, message string) {
 
 // Fatalf ...
 func (e *LogEntry) Fatalf(ctx context.Context, message string, args ...interface{}) {
-	e.log(ctx, SeverityCritical, fmt.Sprintf(message, args...)).dispatch()
+	e.logf(ctx, SeverityCritical, message, args...).dispatch()
 	os.Exit(1)
 }
+
+// Alert logs the message without exiting, unlike Fatal.
+func (e *LogEntry) Alert(ctx context.Context, message string) {
+	e.log(ctx, SeverityAlert, message).dispatch()
+}
+
+// Alertf ...
+func (e *LogEntry) Alertf(ctx context.Context, message string, args ...interface{}) {
+	e.logf(ctx, SeverityAlert, message, args...).dispatch()
+}
+
+// Emergency logs the message without exiting, unlike Fatal.
+func (e *LogEntry) Emergency(ctx context.Context, message string) {
+	e.log(ctx, SeverityEmergency, message).dispatch()
+}
+
+// Emergencyf ...
+func (e *LogEntry) Emergencyf(ctx context.Context, message string, args ...interface{}) {
+	e.logf(ctx, SeverityEmergency, message, args...).dispatch()
+}
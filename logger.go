@@ -1,8 +1,13 @@
 package clogger
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
@@ -21,24 +26,58 @@ type Logger interface {
 	SetEncoder(enc Encoder)
 }
 
+// Sink receives every log entry and event that the logger outputs, next to
+// the encoded stream written to stdout/stderr, e.g. to export them elsewhere.
+type Sink interface {
+	WriteLogEntry(entry *LogEntry) error
+	WriteEvent(event *Event) error
+}
+
 type DefaultLogger struct {
+	// The minimum severity output, accessed atomically.
+	level int32
+
 	eventOpts    []EventOption
 	logEntryOpts []LogEntryOption
+	sinks        []Sink
+	sampler      Sampler
+	throttle     *throttle
+	redactor     *Redactor
+	limits       *Limits
+	errReporter  *errorReporter
+	verbosity    *verbosity
+	named        namedOverrides
+	out          io.Writer
 	mu           sync.Mutex
 	enc          Encoder
+
+	// Serializes the changes of the runtime settings, see updateSettings. Locked before mu.
+	settingsMu sync.Mutex
 }
 
 func (l *DefaultLogger) SetEncoder(enc Encoder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	l.enc = enc
 }
 
-func NewDefaultLogger() Logger {
-	return &DefaultLogger{
+// NewDefaultLogger returns a DefaultLogger writing JSON to stdout/stderr. It returns the concrete type,
+// not a Logger, for the settings missing from the Logger interface, e.g. SetSinks, to be reachable.
+func NewDefaultLogger() *DefaultLogger {
+	l := &DefaultLogger{
 		eventOpts:    make([]EventOption, 0),
 		logEntryOpts: make([]LogEntryOption, 0),
+		sinks:        make([]Sink, 0),
 		mu:           sync.Mutex{},
 		enc:          &JSONEncoder{},
+		errReporter:  newErrorReporter(),
+		verbosity:    newVerbosity(),
+		named:        newNamedOverrides(),
 	}
+	l.throttle = newThrottle(l.writeLogEntry)
+
+	return l
 }
 
 func (l *DefaultLogger) SetEventOptions(opts ...EventOption) {
@@ -55,6 +94,150 @@ func (l *DefaultLogger) SetLogEntryOptions(opts ...LogEntryOption) {
 	l.logEntryOpts = opts
 }
 
+// SetSinks registers the sinks which receive every log entry and event, next to the encoded stream.
+func (l *DefaultLogger) SetSinks(sinks ...Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sinks = sinks
+}
+
+func (l *DefaultLogger) Sinks() []Sink {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.sinks
+}
+
+// SetSampler registers the sampler deciding which log entries and events get output. Defaults to none, keeping everything.
+func (l *DefaultLogger) SetSampler(s Sampler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sampler = s
+}
+
+func (l *DefaultLogger) Sampler() Sampler {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.sampler
+}
+
+// SetRedactor registers the redactor applied to every log entry and event before they get
+// written to the sinks and encoded. Defaults to none.
+func (l *DefaultLogger) SetRedactor(r *Redactor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.redactor = r
+}
+
+func (l *DefaultLogger) Redactor() *Redactor {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.redactor
+}
+
+// SetErrorHandler registers the handler receiving the logger's internal failures. Defaults to writing them to stderr.
+func (l *DefaultLogger) SetErrorHandler(h ErrorHandler) {
+	l.errReporter.setHandler(h)
+}
+
+func (l *DefaultLogger) reportError(err error) {
+	l.errReporter.report(err)
+}
+
+// SetLevel sets the minimum severity of the log entries and events to output. Defaults to outputting everything.
+func (l *DefaultLogger) SetLevel(sev Severity) {
+	atomic.StoreInt32(&l.level, int32(sev))
+}
+
+func (l *DefaultLogger) Level() Severity {
+	return Severity(atomic.LoadInt32(&l.level))
+}
+
+// SetVerbosity sets the global verbosity level, enabling V(level) for every level up to it. Defaults to 0.
+func (l *DefaultLogger) SetVerbosity(level int) {
+	l.verbosity.setLevel(level)
+}
+
+func (l *DefaultLogger) Verbosity() int {
+	return int(atomic.LoadInt32(&l.verbosity.level))
+}
+
+// SetVModule overrides the global verbosity level per file or package, resolved from the caller's file.
+// The spec is a comma separated list of pattern=level, e.g. "server=2,internal/db/*=3", the first match wins.
+// Patterns without a "/" match the file's name without its ".go" extension, the others the trailing
+// elements of its path. An empty spec removes the overrides.
+func (l *DefaultLogger) SetVModule(spec string) error {
+	return l.verbosity.setVModule(spec)
+}
+
+func (l *DefaultLogger) VModule() string {
+	return l.verbosity.vmoduleSpec()
+}
+
+// V returns a Verbose logging only if level is enabled for the caller, see SetVerbosity and SetVModule.
+func (l *DefaultLogger) V(level int) Verbose {
+	return l.v(level, 1)
+}
+
+// v returns a Verbose bound to l, resolving the verbosity of the caller skip frames above.
+func (l *DefaultLogger) v(level int, skip int) Verbose {
+	return newVerbose(l, l.verbosity.enabled(level, skip+1), level)
+}
+
+// SetOutput writes every encoded log entry and event to w. Defaults to nil, writing
+// up to Info to stdout and the rest to stderr.
+func (l *DefaultLogger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.out = w
+}
+
+func (l *DefaultLogger) Output() io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.out
+}
+
+// SetLimits bounds the size of the log entries and events. Defaults to no limits.
+func (l *DefaultLogger) SetLimits(lim Limits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limits = &lim
+}
+
+func (l *DefaultLogger) Limits() *Limits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limits
+}
+
+// SetRateLimit allows up to burst log entries of the given severity per call site and message template,
+// for every window. Once the window is over, the dropped entries are reported through a summary entry.
+// A zero burst or window removes the limit.
+func (l *DefaultLogger) SetRateLimit(sev Severity, burst int, window time.Duration) {
+	l.throttle.setRateLimit(sev, burst, window)
+}
+
+func (l *DefaultLogger) rateLimited(sev Severity) bool {
+	return l.throttle.rateLimited(sev)
+}
+
+// SetDedupe collapses the identical log entries of the given severity, i.e. with the same message and fields,
+// logged within window after the first one. Once the window is over, the repeated entries are reported
+// through a summary entry ("repeated 4,812 times since ..."). A zero window removes the deduplication.
+func (l *DefaultLogger) SetDedupe(sev Severity, window time.Duration) {
+	l.throttle.setDedupe(sev, window)
+}
+
 func (l *DefaultLogger) EventOptions() []EventOption {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -70,29 +253,144 @@ func (l *DefaultLogger) LogEntryOptions() []LogEntryOption {
 }
 
 func (l *DefaultLogger) StreamLogEntry(entry *LogEntry) {
-	output, err := l.enc.EncodeLogEntry(entry)
-	if err != nil {
+	if !entry.forced {
+		level, sampler := l.admission(entry.name)
+		if entry.severity < level {
+			return
+		}
+
+		if sampler != nil && !sampler.SampleLogEntry(entry) {
+			return
+		}
+	}
+
+	if !l.throttle.allow(entry) {
+		return
+	}
+
+	l.writeLogEntry(entry)
+}
+
+func (l *DefaultLogger) writeLogEntry(entry *LogEntry) {
+	p := l.pipeline(entry.name)
+
+	if p.redactor != nil {
+		p.redactor.redactLogEntry(entry)
+	}
+
+	for _, fn := range entry.writeFns {
+		fn()
+	}
+
+	if p.limits == nil {
+		l.outputLogEntry(entry, &Limits{}, p)
 		return
 	}
 
-	switch {
-	case entry.severity > SeverityInfo:
-		_, _ = os.Stderr.Write(output)
-	default:
-		_, _ = os.Stdout.Write(output)
+	for _, part := range p.limits.limitLogEntry(entry) {
+		l.outputLogEntry(part, p.limits, p)
 	}
 }
 
+func (l *DefaultLogger) outputLogEntry(entry *LogEntry, lim *Limits, p pipeline) {
+	output, err := lim.encode(func() ([]byte, error) {
+		return p.enc.EncodeLogEntry(entry)
+	}, &entry.message, entry.fields)
+
+	for _, sink := range p.sinks {
+		if err := sink.WriteLogEntry(entry); err != nil {
+			l.reportError(fmt.Errorf("clogger: sink %T: %w", sink, err))
+		}
+	}
+
+	l.write(p.out, entry.severity, output, err)
+}
+
 func (l *DefaultLogger) StreamEvent(event *Event) {
-	output, err := l.enc.EncodeEvent(event)
+	if !event.forced {
+		level, sampler := l.admission(event.name)
+		if event.severity < level {
+			return
+		}
+
+		if sampler != nil && !sampler.SampleEvent(event) {
+			return
+		}
+	}
+
+	p := l.pipeline(event.name)
+
+	if p.redactor != nil {
+		p.redactor.redactEvent(event)
+	}
+
+	for _, fn := range event.writeHooks() {
+		fn()
+	}
+
+	lim := p.limits
+	if lim == nil {
+		lim = &Limits{}
+	}
+	lim.limitEvent(event)
+
+	output, err := lim.encode(func() ([]byte, error) {
+		return p.enc.EncodeEvent(event)
+	}, &event.message, event.fields, event.errFields, event.labels)
+
+	for _, sink := range p.sinks {
+		if err := sink.WriteEvent(event); err != nil {
+			l.reportError(fmt.Errorf("clogger: sink %T: %w", sink, err))
+		}
+	}
+
+	l.write(p.out, event.severity, output, err)
+}
+
+// pipeline is what a log entry or an event goes through once admitted. It is read at once,
+// for a concurrent reconfiguration (see WatchConfig) never to apply halfway through one.
+type pipeline struct {
+	redactor *Redactor
+	limits   *Limits
+	enc      Encoder
+	sinks    []Sink
+	out      io.Writer
+}
+
+// pipeline returns the pipeline of the given NamedLogger name, if any.
+func (l *DefaultLogger) pipeline(name string) pipeline {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return pipeline{
+		redactor: l.redactor,
+		limits:   l.limits,
+		enc:      l.enc,
+		sinks:    l.sinksFor(name),
+		out:      l.out,
+	}
+}
+
+// write outputs the encoded entry or event. Outputs with fields which could not be encoded
+// are still written, their values being replaced with placeholders.
+func (l *DefaultLogger) write(w io.Writer, sev Severity, output []byte, err error) {
 	if err != nil {
-		return
+		l.reportError(err)
+
+		var fe *FieldsError
+		if !errors.As(err, &fe) {
+			return
+		}
+	}
+
+	if w == nil {
+		w = os.Stdout
+		if sev > SeverityInfo {
+			w = os.Stderr
+		}
 	}
 
-	switch {
-	case event.severity > SeverityInfo:
-		_, _ = os.Stderr.Write(output)
-	default:
-		_, _ = os.Stdout.Write(output)
+	if _, err := w.Write(output); err != nil {
+		l.reportError(fmt.Errorf("clogger: write: %w", err))
 	}
 }
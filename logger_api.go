@@ -0,0 +1,125 @@
+package clogger
+
+import (
+	"context"
+)
+
+/*
+	The package-level API, bound to a DefaultLogger instance instead of the global logger.
+*/
+
+// NewEvent starts an event going through l. The returned context carries l too, see WithLogger,
+// for the log entries and events created with it to go through l as well.
+func (l *DefaultLogger) NewEvent(ctx context.Context, message string) (context.Context, *Event) {
+	return newEvent(WithLogger(ctx, l), l, message)
+}
+
+// With registers a field onto a new log entry going through l.
+func (l *DefaultLogger) With(key string, value interface{}) Loggable {
+	return boundLogEntry(l).With(key, value)
+}
+
+// WithHTTPRequest registers the HTTP request that a new log entry going through l refers to.
+func (l *DefaultLogger) WithHTTPRequest(req *HTTPRequest) Loggable {
+	return boundLogEntry(l).WithHTTPRequest(req)
+}
+
+// Log creates a new log entry going through l, with the given severity, custom ones included.
+func (l *DefaultLogger) Log(ctx context.Context, sev Severity, msg string) {
+	boundLogEntry(l).Log(ctx, sev, msg)
+}
+
+// Logf creates a new log entry going through l, with the given severity, custom ones included.
+func (l *DefaultLogger) Logf(ctx context.Context, sev Severity, msg string, args ...interface{}) {
+	boundLogEntry(l).Logf(ctx, sev, msg, args...)
+}
+
+// Trace creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Trace(ctx context.Context, msg string) {
+	boundLogEntry(l).Trace(ctx, msg)
+}
+
+// Tracef creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Tracef(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Tracef(ctx, msg, args...)
+}
+
+// Debug creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Debug(ctx context.Context, msg string) {
+	boundLogEntry(l).Debug(ctx, msg)
+}
+
+// Debugf creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Debugf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Debugf(ctx, msg, args...)
+}
+
+// Info creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Info(ctx context.Context, msg string) {
+	boundLogEntry(l).Info(ctx, msg)
+}
+
+// Infof creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Infof(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Infof(ctx, msg, args...)
+}
+
+// Notice creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Notice(ctx context.Context, msg string) {
+	boundLogEntry(l).Notice(ctx, msg)
+}
+
+// Noticef creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Noticef(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Noticef(ctx, msg, args...)
+}
+
+// Warn creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Warn(ctx context.Context, msg string) {
+	boundLogEntry(l).Warn(ctx, msg)
+}
+
+// Warnf creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Warnf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Warnf(ctx, msg, args...)
+}
+
+// Error creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Error(ctx context.Context, msg string) {
+	boundLogEntry(l).Error(ctx, msg)
+}
+
+// Errorf creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Errorf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Errorf(ctx, msg, args...)
+}
+
+// Fatal creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Fatal(ctx context.Context, msg string) {
+	boundLogEntry(l).Fatal(ctx, msg)
+}
+
+// Fatalf creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Fatalf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Fatalf(ctx, msg, args...)
+}
+
+// Alert creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Alert(ctx context.Context, msg string) {
+	boundLogEntry(l).Alert(ctx, msg)
+}
+
+// Alertf creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Alertf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Alertf(ctx, msg, args...)
+}
+
+// Emergency creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Emergency(ctx context.Context, msg string) {
+	boundLogEntry(l).Emergency(ctx, msg)
+}
+
+// Emergencyf creates a new log entry going through l, with the given severity.
+func (l *DefaultLogger) Emergencyf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(l).Emergencyf(ctx, msg, args...)
+}
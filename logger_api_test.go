@@ -0,0 +1,70 @@
+package clogger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultLoggerInstance(t *testing.T) {
+	g, global := newRecordingLogger()
+	// Cleanups run once the parallel subtests are over
+	setGlobal(t, g)
+	t.Cleanup(func() {
+		assert.Empty(t, global.entries)
+		assert.Empty(t, global.events)
+	})
+
+	for _, name := range []string{"first", "second"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			l, sink := newRecordingLogger()
+			l.SetLogEntryOptions(func(ctx context.Context, entry *LogEntry) {
+				entry.fields.add("logger", name)
+			})
+
+			ctx, ev := l.NewEvent(context.Background(), name)
+			Info(ctx, "Child through the context")
+			l.With("key", "value").Warn(ctx, "Bound child")
+			ev.End()
+
+			l.Errorf(context.Background(), "Bound %s", name)
+
+			require.Len(t, sink.events, 1)
+			assert.Equal(t, name, sink.events[0].message)
+			assert.Equal(t, SeverityWarn, sink.events[0].severity)
+
+			require.Len(t, sink.entries, 3)
+			for _, entry := range sink.entries {
+				assert.Equal(t, name, entry.fields.retrieve("logger"))
+			}
+			assert.Equal(t, "Bound "+name, sink.entries[2].message)
+		})
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	g, global := newRecordingLogger()
+	setGlobal(t, g)
+
+	l, sink := newRecordingLogger()
+
+	ctx := WithLogger(context.Background(), l)
+	Info(ctx, "Through the context")
+	g.Info(ctx, "Bound to the global logger")
+
+	ctx, ev := NewEvent(ctx, "event")
+	Set(ctx, "key", "value")
+	ev.End()
+
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, "Through the context", sink.entries[0].message)
+	require.Len(t, sink.events, 1)
+	require.Len(t, global.entries, 1)
+	assert.Equal(t, "Bound to the global logger", global.entries[0].message)
+	assert.Empty(t, global.events)
+}
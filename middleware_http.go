@@ -0,0 +1,168 @@
+package clogger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+type HTTPOption func(m *httpMiddleware)
+
+// WithDebugHeader forces debug output, see ContextWithForcedDebug, for the requests carrying
+// the header with a value signed with key, see SignDebugHeader. An empty header or key, e.g. left unset
+// in the configuration, disables it: no request gets debug output forced through a header.
+func WithDebugHeader(header string, key []byte) HTTPOption {
+	return func(m *httpMiddleware) {
+		if header == "" || len(key) == 0 {
+			m.debugHeader, m.debugKey = "", nil
+			return
+		}
+
+		m.debugHeader = header
+		m.debugKey = key
+	}
+}
+
+// WithDebugMaxTTL sets the furthest expiry accepted for the header registered by WithDebugHeader,
+// from the time the request is received. Defaults to an hour.
+func WithDebugMaxTTL(ttl time.Duration) HTTPOption {
+	return func(m *httpMiddleware) {
+		m.debugMaxTTL = ttl
+	}
+}
+
+// WithDebugQueryParam forces debug output, see ContextWithForcedDebug, for the requests carrying
+// the query param with one of the allowed values, e.g. WithDebugQueryParam("debug", token).
+// The param is left out of the logged request URL.
+func WithDebugQueryParam(param string, allowed ...string) HTTPOption {
+	return func(m *httpMiddleware) {
+		m.debugParam = param
+		for _, v := range allowed {
+			m.debugValues[v] = struct{}{}
+		}
+	}
+}
+
+type httpMiddleware struct {
+	debugHeader string
+	debugKey    []byte
+	debugMaxTTL time.Duration
+	debugParam  string
+	debugValues map[string]struct{}
+}
+
+// HTTPMiddleware wraps every incoming request in an Event, which is passed down through the request's context.
+// The trace context and the baggage received through the request headers are made available
+// to WithTraceContext and WithBaggageLabels.
+// Once the request is served, the event is decorated with an HTTPRequest describing both the request and
+// the response, its severity is raised according to the response status, and it gets ended.
+// Debug output can be forced per request, see WithDebugHeader and WithDebugQueryParam.
+func HTTPMiddleware(next http.Handler, opts ...HTTPOption) http.Handler {
+	m := &httpMiddleware{debugMaxTTL: defaultDebugMaxTTL, debugValues: make(map[string]struct{})}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx := ContextWithTraceHeaders(r.Context(), r.Header)
+		ctx = contextWithBaggage(ctx, r.Header.Get(baggageHeader))
+		if m.forceDebug(r) {
+			ctx = ContextWithForcedDebug(ctx)
+		}
+		ctx, ev := NewEvent(ctx, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+		defer ev.End()
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		req := NewHTTPRequest(r)
+		req.URL = m.loggedURL(r.URL)
+		req.Status = rec.status()
+		req.ResponseSize = rec.size
+		req.Latency = time.Since(start)
+		ev.SetHTTPRequest(req)
+
+		ev.raiseSeverity(severityFromHTTPStatus(req.Status))
+	})
+}
+
+func severityFromHTTPStatus(status int) Severity {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return SeverityError
+	case status >= http.StatusBadRequest:
+		return SeverityWarn
+	default:
+		return SeverityInfo
+	}
+}
+
+// responseRecorder keeps track of the status code
+// and the number of bytes written in a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	code int
+	size int64
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	if rr.code == 0 {
+		rr.code = code
+	}
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.code == 0 {
+		rr.code = http.StatusOK
+	}
+	n, err := rr.ResponseWriter.Write(b)
+	rr.size += int64(n)
+	return n, err
+}
+
+func (rr *responseRecorder) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection, e.g. for websockets, if the wrapped ResponseWriter allows it.
+func (rr *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil && rr.code == 0 {
+		rr.code = http.StatusSwitchingProtocols
+	}
+
+	return conn, rw, err
+}
+
+// Push initiates an HTTP/2 server push, if the wrapped ResponseWriter allows it.
+func (rr *responseRecorder) Push(target string, opts *http.PushOptions) error {
+	if p, ok := rr.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the wrapped ResponseWriter, e.g. for http.ResponseController.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+func (rr *responseRecorder) status() int {
+	if rr.code == 0 {
+		return http.StatusOK
+	}
+	return rr.code
+}
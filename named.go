@@ -0,0 +1,325 @@
+package clogger
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	loggerNameKey = "logger"
+)
+
+// NamedLogger is the logger of a component, e.g. Named("billing").Named("stripe"). It stamps its log entries
+// and events with a logger field holding its dotted name, "billing.stripe", and goes through its root logger,
+// sharing its configuration. A root DefaultLogger can override the level, sampling and sinks per name prefix,
+// see DefaultLogger.SetNamedLevel, SetNamedSampler and SetNamedSinks.
+type NamedLogger struct {
+	// Nil for the global logger, resolved whenever used.
+	root Logger
+	name string
+}
+
+// Named returns a NamedLogger going through the global logger, whichever it is when logging.
+func Named(name string) *NamedLogger {
+	return &NamedLogger{name: name}
+}
+
+// Named returns a NamedLogger going through l.
+func (l *DefaultLogger) Named(name string) *NamedLogger {
+	return &NamedLogger{root: l, name: name}
+}
+
+// Named returns a child NamedLogger, named after both, e.g. "billing.stripe".
+func (n *NamedLogger) Named(name string) *NamedLogger {
+	return &NamedLogger{root: n.root, name: n.name + "." + name}
+}
+
+// Name returns the dotted name of the logger.
+func (n *NamedLogger) Name() string {
+	return n.name
+}
+
+func (n *NamedLogger) rootLogger() Logger {
+	if n.root != nil {
+		return n.root
+	}
+
+	return logger()
+}
+
+func (n *NamedLogger) StreamLogEntry(entry *LogEntry) {
+	entry.name = n.name
+	entry.fields.add(loggerNameKey, n.name)
+	n.rootLogger().StreamLogEntry(entry)
+}
+
+func (n *NamedLogger) StreamEvent(event *Event) {
+	event.name = n.name
+	event.fields.add(loggerNameKey, n.name)
+	n.rootLogger().StreamEvent(event)
+}
+
+// SetLogEntryOptions sets the options of the root logger, shared by every NamedLogger.
+func (n *NamedLogger) SetLogEntryOptions(opts ...LogEntryOption) {
+	n.rootLogger().SetLogEntryOptions(opts...)
+}
+
+func (n *NamedLogger) LogEntryOptions() []LogEntryOption {
+	return n.rootLogger().LogEntryOptions()
+}
+
+// SetEventOptions sets the options of the root logger, shared by every NamedLogger.
+func (n *NamedLogger) SetEventOptions(opts ...EventOption) {
+	n.rootLogger().SetEventOptions(opts...)
+}
+
+func (n *NamedLogger) EventOptions() []EventOption {
+	return n.rootLogger().EventOptions()
+}
+
+// SetEncoder sets the encoder of the root logger, shared by every NamedLogger.
+func (n *NamedLogger) SetEncoder(enc Encoder) {
+	n.rootLogger().SetEncoder(enc)
+}
+
+// Redactor returns the redactor of the root logger, shared by every NamedLogger.
+func (n *NamedLogger) Redactor() *Redactor {
+	return redactorOf(n.rootLogger())
+}
+
+func (n *NamedLogger) rateLimited(sev Severity) bool {
+	r, ok := n.rootLogger().(interface{ rateLimited(Severity) bool })
+	return ok && r.rateLimited(sev)
+}
+
+func (n *NamedLogger) reportError(err error) {
+	reportError(n.rootLogger(), err)
+}
+
+// NewEvent starts an event going through n. The returned context carries n too, see WithLogger,
+// for the log entries and events created with it to go through n as well.
+func (n *NamedLogger) NewEvent(ctx context.Context, message string) (context.Context, *Event) {
+	return newEvent(WithLogger(ctx, n), n, message)
+}
+
+// With registers a field onto a new log entry going through n.
+func (n *NamedLogger) With(key string, value interface{}) Loggable {
+	return boundLogEntry(n).With(key, value)
+}
+
+// WithHTTPRequest registers the HTTP request that a new log entry going through n refers to.
+func (n *NamedLogger) WithHTTPRequest(req *HTTPRequest) Loggable {
+	return boundLogEntry(n).WithHTTPRequest(req)
+}
+
+// Log creates a new log entry going through n, with the given severity, custom ones included.
+func (n *NamedLogger) Log(ctx context.Context, sev Severity, msg string) {
+	boundLogEntry(n).Log(ctx, sev, msg)
+}
+
+// Logf creates a new log entry going through n, with the given severity, custom ones included.
+func (n *NamedLogger) Logf(ctx context.Context, sev Severity, msg string, args ...interface{}) {
+	boundLogEntry(n).Logf(ctx, sev, msg, args...)
+}
+
+// Trace creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Trace(ctx context.Context, msg string) {
+	boundLogEntry(n).Trace(ctx, msg)
+}
+
+// Tracef creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Tracef(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Tracef(ctx, msg, args...)
+}
+
+// Debug creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Debug(ctx context.Context, msg string) {
+	boundLogEntry(n).Debug(ctx, msg)
+}
+
+// Debugf creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Debugf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Debugf(ctx, msg, args...)
+}
+
+// Info creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Info(ctx context.Context, msg string) {
+	boundLogEntry(n).Info(ctx, msg)
+}
+
+// Infof creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Infof(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Infof(ctx, msg, args...)
+}
+
+// Notice creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Notice(ctx context.Context, msg string) {
+	boundLogEntry(n).Notice(ctx, msg)
+}
+
+// Noticef creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Noticef(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Noticef(ctx, msg, args...)
+}
+
+// Warn creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Warn(ctx context.Context, msg string) {
+	boundLogEntry(n).Warn(ctx, msg)
+}
+
+// Warnf creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Warnf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Warnf(ctx, msg, args...)
+}
+
+// Error creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Error(ctx context.Context, msg string) {
+	boundLogEntry(n).Error(ctx, msg)
+}
+
+// Errorf creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Errorf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Errorf(ctx, msg, args...)
+}
+
+// Fatal creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Fatal(ctx context.Context, msg string) {
+	boundLogEntry(n).Fatal(ctx, msg)
+}
+
+// Fatalf creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Fatalf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Fatalf(ctx, msg, args...)
+}
+
+// Alert creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Alert(ctx context.Context, msg string) {
+	boundLogEntry(n).Alert(ctx, msg)
+}
+
+// Alertf creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Alertf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Alertf(ctx, msg, args...)
+}
+
+// Emergency creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Emergency(ctx context.Context, msg string) {
+	boundLogEntry(n).Emergency(ctx, msg)
+}
+
+// Emergencyf creates a new log entry going through n, with the given severity.
+func (n *NamedLogger) Emergencyf(ctx context.Context, msg string, args ...interface{}) {
+	boundLogEntry(n).Emergencyf(ctx, msg, args...)
+}
+
+// namedOverrides hold the settings of a DefaultLogger overridden per NamedLogger name prefix.
+type namedOverrides struct {
+	levels   map[string]Severity
+	samplers map[string]Sampler
+	sinks    map[string][]Sink
+}
+
+func newNamedOverrides() namedOverrides {
+	return namedOverrides{
+		levels:   make(map[string]Severity),
+		samplers: make(map[string]Sampler),
+		sinks:    make(map[string][]Sink),
+	}
+}
+
+// matchNamed returns the longest prefix of name, by its dotted elements, for which has is true, if any.
+// E.g. "billing.stripe" is tried before "billing".
+func matchNamed(name string, has func(prefix string) bool) (string, bool) {
+	for prefix := name; prefix != ""; {
+		if has(prefix) {
+			return prefix, true
+		}
+
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+
+	return "", false
+}
+
+// SetNamedLevel overrides the minimum severity for the NamedLoggers named prefix or under it, e.g. "billing"
+// for both "billing" and "billing.stripe". The longest matching prefix wins.
+func (l *DefaultLogger) SetNamedLevel(prefix string, sev Severity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.named.levels[prefix] = sev
+}
+
+// SetNamedSampler overrides the sampler for the NamedLoggers named prefix or under it, a nil one keeping
+// everything. The longest matching prefix wins.
+func (l *DefaultLogger) SetNamedSampler(prefix string, s Sampler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.named.samplers[prefix] = s
+}
+
+// SetNamedSinks replaces the sinks for the NamedLoggers named prefix or under it, e.g. to send
+// a component's log entries and events elsewhere. The longest matching prefix wins.
+func (l *DefaultLogger) SetNamedSinks(prefix string, sinks ...Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.named.sinks[prefix] = sinks
+}
+
+// ResetNamed removes the overrides registered for prefix.
+func (l *DefaultLogger) ResetNamed(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.named.levels, prefix)
+	delete(l.named.samplers, prefix)
+	delete(l.named.sinks, prefix)
+}
+
+// admission returns the minimum severity and the sampler of the given NamedLogger name, read together
+// for a change of settings to apply at once, see setSettings.
+func (l *DefaultLogger) admission(name string) (Severity, Sampler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.levelFor(name), l.samplerFor(name)
+}
+
+// levelFor returns the minimum severity of the given NamedLogger name. l.mu must be held.
+func (l *DefaultLogger) levelFor(name string) Severity {
+	if name != "" {
+		if prefix, ok := matchNamed(name, func(p string) bool { _, ok := l.named.levels[p]; return ok }); ok {
+			return l.named.levels[prefix]
+		}
+	}
+
+	return l.Level()
+}
+
+// samplerFor returns the sampler of the given NamedLogger name. l.mu must be held.
+func (l *DefaultLogger) samplerFor(name string) Sampler {
+	if name != "" {
+		if prefix, ok := matchNamed(name, func(p string) bool { _, ok := l.named.samplers[p]; return ok }); ok {
+			return l.named.samplers[prefix]
+		}
+	}
+
+	return l.sampler
+}
+
+// sinksFor returns the sinks of the given NamedLogger name. l.mu must be held.
+func (l *DefaultLogger) sinksFor(name string) []Sink {
+	if name != "" {
+		if prefix, ok := matchNamed(name, func(p string) bool { _, ok := l.named.sinks[p]; return ok }); ok {
+			return l.named.sinks[prefix]
+		}
+	}
+
+	return l.sinks
+}
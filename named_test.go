@@ -0,0 +1,129 @@
+package clogger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamed(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	stripe := Named("billing").Named("stripe")
+	assert.Equal(t, "billing.stripe", stripe.Name())
+
+	ctx, ev := stripe.NewEvent(context.Background(), "charge")
+	Info(ctx, "Child through the context")
+	ev.End()
+	stripe.With("key", "value").Warnf(context.Background(), "Retrying %d", 1)
+
+	require.Len(t, sink.entries, 2)
+	for _, entry := range sink.entries {
+		assert.Equal(t, "billing.stripe", entry.fields.retrieve(loggerNameKey))
+	}
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, "billing.stripe", sink.events[0].fields.retrieve(loggerNameKey))
+}
+
+func TestNamedOverrides(t *testing.T) {
+	l, sink := newRecordingLogger()
+	billingSink := &recordingSink{}
+	l.SetNamedLevel("billing", SeverityWarn)
+	l.SetNamedLevel("billing.stripe", SeverityDebug)
+	l.SetNamedSampler("search", NewTraceSampler(0))
+	l.SetNamedSinks("billing", billingSink)
+
+	ctx := context.Background()
+	l.Named("billing").Info(ctx, "Quieted")
+	l.Named("billing").Named("invoices").Warn(ctx, "Inherited")
+	l.Named("billing").Named("stripe").Debug(ctx, "Overridden")
+	l.Named("billingx").Info(ctx, "Not a prefix")
+	l.Named("search").Info(ctx, "Sampled out")
+	l.Info(ctx, "Unnamed")
+
+	require.Len(t, billingSink.entries, 2)
+	assert.Equal(t, "Inherited", billingSink.entries[0].message)
+	assert.Equal(t, "Overridden", billingSink.entries[1].message)
+
+	require.Len(t, sink.entries, 2)
+	assert.Equal(t, "Not a prefix", sink.entries[0].message)
+	assert.Equal(t, "Unnamed", sink.entries[1].message)
+
+	l.ResetNamed("billing")
+	l.Named("billing").Info(ctx, "Reset")
+	require.Len(t, sink.entries, 3)
+	assert.Equal(t, "Reset", sink.entries[2].message)
+}
+
+func TestMatchNamed(t *testing.T) {
+	prefixes := map[string]bool{"billing": true, "billing.stripe.api": true}
+	has := func(p string) bool { return prefixes[p] }
+
+	for name, want := range map[string]string{
+		"billing":               "billing",
+		"billing.stripe":        "billing",
+		"billing.stripe.api":    "billing.stripe.api",
+		"billing.stripe.api.v2": "billing.stripe.api",
+		"billingx":              "",
+		"search.billing":        "",
+	} {
+		got, ok := matchNamed(name, has)
+		assert.Equal(t, want, got, name)
+		assert.Equal(t, want != "", ok, name)
+	}
+}
+
+func TestNamedConfig(t *testing.T) {
+	t.Setenv("CLOGGER_NAMED_LEVELS", "billing=warn, billing.stripe=debug")
+	path := writeConfig(t, "logger.yaml", "named_sample_rates: [search=0.1]\n")
+
+	c, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Severity{"billing": SeverityWarn, "billing.stripe": SeverityDebug}, c.NamedLevels)
+	assert.Equal(t, map[string]float64{"search": 0.1}, c.NamedSampleRates)
+
+	l, err := FromConfig(c)
+	require.NoError(t, err)
+	level, _ := l.admission("billing.invoices")
+	assert.Equal(t, SeverityWarn, level)
+	_, sampler := l.admission("search.api")
+	assert.InDelta(t, 0.1, sampler.(*TraceSampler).Rate(), 0.0001)
+
+	t.Setenv("CLOGGER_NAMED_LEVELS", "billing")
+	_, err = LoadConfig("")
+	assert.Error(t, err)
+}
+
+func TestNamedConfigObjects(t *testing.T) {
+	for name, content := range map[string]string{
+		"logger.yaml": "named_levels:\n  billing: warn\n  billing.stripe: debug\nnamed_sample_rates:\n  search: 0.1\n",
+		"logger.json": `{"named_levels": {"billing": "warn", "billing.stripe": "debug"}, "named_sample_rates": {"search": 0.1}}`,
+	} {
+		c, err := LoadConfig(writeConfig(t, name, content))
+		require.NoError(t, err, name)
+		assert.Equal(t, map[string]Severity{"billing": SeverityWarn, "billing.stripe": SeverityDebug}, c.NamedLevels, name)
+		assert.Equal(t, map[string]float64{"search": 0.1}, c.NamedSampleRates, name)
+	}
+
+	_, err := LoadConfig(writeConfig(t, "logger.yaml", "level:\n  billing: warn\n"))
+	var ce *ConfigError
+	require.True(t, errors.As(err, &ce))
+	assert.Equal(t, "level", ce.Key)
+}
+
+func TestNamedConfigReplacesOverrides(t *testing.T) {
+	l := NewDefaultLogger()
+	l.SetNamedLevel("billing", SeverityError)
+	l.SetNamedLevel("search", SeverityError)
+
+	require.NoError(t, l.configure(Config{NamedLevels: map[string]Severity{"billing": SeverityWarn}}))
+
+	level, _ := l.admission("billing")
+	assert.Equal(t, SeverityWarn, level)
+	level, _ = l.admission("search")
+	assert.Equal(t, Severity(0), level, "The config should own the named levels")
+}
@@ -5,6 +5,7 @@ import (
 
 	oc "go.opencensus.io/trace"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -21,13 +22,62 @@ type LogEntryOption func(ctx context.Context, entry *LogEntry)
 
 type EventOption func(ctx context.Context, event *Event)
 
-func WithOpenTelemetryTrace() EventOption {
+type TraceOption func(c *traceConfig)
+
+type traceConfig struct {
+	tracerProvider trace.TracerProvider
+	startSpan      bool
+}
+
+// WithTracerProvider sets the provider used to start new OpenTelemetry spans.
+// Defaults to the globally registered one. It has no effect on the OpenCensus options.
+func WithTracerProvider(tp trace.TracerProvider) TraceOption {
+	return func(c *traceConfig) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithStartSpan starts a new span when there isn't one already active in ctx.
+// An event's span lives as long as the event does, and it is passed down through the event's context.
+// A log entry's span is ended right away.
+func WithStartSpan() TraceOption {
+	return func(c *traceConfig) {
+		c.startSpan = true
+	}
+}
+
+func newTraceConfig(opts []TraceOption) *traceConfig {
+	c := &traceConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *traceConfig) tracer() trace.Tracer {
+	tp := c.tracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return tp.Tracer("github.com/foae/clogger")
+}
+
+// WithOpenTelemetryTrace stamps the event with the IDs of the OpenTelemetry span active in ctx.
+func WithOpenTelemetryTrace(opts ...TraceOption) EventOption {
+	c := newTraceConfig(opts)
+
 	return func(ctx context.Context, event *Event) {
-		_, span := otel.Tracer("").Start(ctx, "clogger.Event.WithOpenTelemetryTrace")
-		span.AddEvent(event.message)
-		defer span.End()
+		span := trace.SpanFromContext(ctx)
+		if !span.SpanContext().IsValid() {
+			if !c.startSpan {
+				return
+			}
 
-		// TODO: offer the possibility to pass a Tracer/TraceProvider
+			event.ctx, span = c.tracer().Start(ctx, event.message)
+			event.onEnd(func() { span.End() })
+		}
 
 		event.fields.add(otelSpanID, span.SpanContext().SpanID().String())   // TODO: the logger should be aware how to encode this key
 		event.fields.add(otelTraceID, span.SpanContext().TraceID().String()) // TODO: the logger should be aware how to encode this key
@@ -35,22 +85,41 @@ func WithOpenTelemetryTrace() EventOption {
 	}
 }
 
-func WithOpenTelemetrySpan() LogEntryOption {
+// WithOpenTelemetrySpan stamps the log entry with the IDs of the OpenTelemetry span active in ctx.
+func WithOpenTelemetrySpan(opts ...TraceOption) LogEntryOption {
+	c := newTraceConfig(opts)
+
 	return func(ctx context.Context, entry *LogEntry) {
-		_, span := otel.Tracer("").Start(ctx, "clogger.LogEntry.WithOpenTelemetryTrace")
-		defer span.End()
+		span := trace.SpanFromContext(ctx)
+		if !span.SpanContext().IsValid() {
+			if !c.startSpan {
+				return
+			}
 
-		// TODO: offer the possibility to pass a Tracer/TraceProvider
+			_, span = c.tracer().Start(ctx, entry.message)
+			defer span.End()
+		}
 
 		entry.fields.add(otelSpanID, span.SpanContext().SpanID().String())   // TODO: the logger should be aware how to encode this key
 		entry.fields.add(otelTraceID, span.SpanContext().TraceID().String()) // TODO: the logger should be aware how to encode this key
+		entry.fields.add(otelSampled, span.SpanContext().IsSampled())        // TODO: the logger should be aware how to encode this key
 	}
 }
 
-func WithOpenCensusTrace() EventOption {
+// WithOpenCensusTrace stamps the event with the IDs of the OpenCensus span active in ctx.
+func WithOpenCensusTrace(opts ...TraceOption) EventOption {
+	c := newTraceConfig(opts)
+
 	return func(ctx context.Context, event *Event) {
-		_, span := oc.StartSpan(ctx, "clogger.Event.WithOpenCensusTrace")
-		defer span.End()
+		span := oc.FromContext(ctx)
+		if span == nil {
+			if !c.startSpan {
+				return
+			}
+
+			event.ctx, span = oc.StartSpan(ctx, event.message)
+			event.onEnd(span.End)
+		}
 
 		event.fields.add(opencensusSpanID, span.SpanContext().SpanID.String())   // TODO: the logger should be aware how to encode this key
 		event.labels.add(opencensusTraceID, span.SpanContext().TraceID.String()) // TODO: the logger should be aware how to encode this key
@@ -58,13 +127,24 @@ func WithOpenCensusTrace() EventOption {
 	}
 }
 
-func WithOpenCensusSpan() LogEntryOption {
+// WithOpenCensusSpan stamps the log entry with the IDs of the OpenCensus span active in ctx.
+func WithOpenCensusSpan(opts ...TraceOption) LogEntryOption {
+	c := newTraceConfig(opts)
+
 	return func(ctx context.Context, entry *LogEntry) {
-		_, span := oc.StartSpan(ctx, "clogger.LogEntry.WithOpenCensusSpan")
-		defer span.End()
+		span := oc.FromContext(ctx)
+		if span == nil {
+			if !c.startSpan {
+				return
+			}
+
+			_, span = oc.StartSpan(ctx, entry.message)
+			defer span.End()
+		}
 
 		entry.fields.add(opencensusSpanID, span.SpanContext().SpanID.String())   // TODO: the logger should be aware how to encode this key
 		entry.fields.add(opencensusTraceID, span.SpanContext().TraceID.String()) // TODO: the logger should be aware how to encode this key
+		entry.fields.add(opencensusSampled, span.SpanContext().IsSampled())      // TODO: the logger should be aware how to encode this key
 	}
 }
 
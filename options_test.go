@@ -0,0 +1,82 @@
+package clogger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	oc "go.opencensus.io/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithOpenTelemetryTraceActiveSpan(t *testing.T) {
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithOpenTelemetryTrace())
+	l.SetLogEntryOptions(WithOpenTelemetrySpan())
+	setGlobal(t, l)
+
+	sc := trace.SpanContextFromContext(tracedContext())
+
+	ctx, ev := NewEvent(tracedContext(), "Traced event")
+	Info(ctx, "Traced log entry")
+	assert.Equal(t, sc.SpanID().String(), ev.fields.retrieve(otelSpanID))
+	assert.Equal(t, sc.TraceID().String(), ev.fields.retrieve(otelTraceID))
+	assert.Equal(t, true, ev.fields.retrieve(otelSampled))
+
+	require.Len(t, ev.logs, 1)
+	assert.Equal(t, sc.SpanID().String(), ev.logs[0].fields.retrieve(otelSpanID))
+	assert.Equal(t, sc.TraceID().String(), ev.logs[0].fields.retrieve(otelTraceID))
+	ev.End()
+
+	// No span in ctx, none gets started
+	_, ev = NewEvent(context.Background(), "Untraced event")
+	assert.Nil(t, ev.fields.retrieve(otelSpanID))
+	assert.Nil(t, ev.fields.retrieve(otelTraceID))
+	ev.End()
+}
+
+func TestWithOpenTelemetryTraceStartSpan(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithOpenTelemetryTrace(WithTracerProvider(tp), WithStartSpan()))
+	setGlobal(t, l)
+
+	ctx, ev := NewEvent(context.Background(), "Traced event")
+	sc := trace.SpanContextFromContext(ctx)
+	require.True(t, sc.IsValid(), "The returned ctx should carry the new span")
+	assert.Equal(t, sc.SpanID().String(), ev.fields.retrieve(otelSpanID))
+	assert.Empty(t, sr.Ended())
+
+	ev.End()
+	require.Len(t, sr.Ended(), 1)
+	assert.Equal(t, "Traced event", sr.Ended()[0].Name())
+	assert.Equal(t, sc.SpanID(), sr.Ended()[0].SpanContext().SpanID())
+}
+
+func TestWithOpenCensusTraceActiveSpan(t *testing.T) {
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithOpenCensusTrace())
+	l.SetLogEntryOptions(WithOpenCensusSpan())
+	setGlobal(t, l)
+
+	ctx, span := oc.StartSpan(context.Background(), "request")
+	defer span.End()
+
+	ctx, ev := NewEvent(ctx, "Traced event")
+	Info(ctx, "Traced log entry")
+	assert.Equal(t, span.SpanContext().SpanID.String(), ev.fields.retrieve(opencensusSpanID))
+	assert.Equal(t, span.SpanContext().TraceID.String(), ev.labels.retrieve(opencensusTraceID))
+
+	require.Len(t, ev.logs, 1)
+	assert.Equal(t, span.SpanContext().SpanID.String(), ev.logs[0].fields.retrieve(opencensusSpanID))
+	ev.End()
+
+	_, ev = NewEvent(context.Background(), "Untraced event")
+	assert.Nil(t, ev.fields.retrieve(opencensusSpanID))
+	ev.End()
+}
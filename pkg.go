@@ -13,6 +13,37 @@ func logger() Logger {
 	return log
 }
 
+// WithLogger returns a copy of ctx carrying l. The log entries and events created with it, or with any
+// context derived from it, go through l instead of the global logger, unless bound to another logger,
+// e.g. by DefaultLogger.Info. It lets libraries and parallel tests use their own logger.
+func WithLogger(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// loggerFrom returns the logger carried by ctx, if any, the global one otherwise.
+func loggerFrom(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerKey).(Logger); ok && l != nil {
+		return l
+	}
+
+	return logger()
+}
+
+// loggableFrom returns the logger carried by ctx, see loggerFrom, for the package-level API to delegate to its
+// instance methods, e.g. DefaultLogger.Info. Loggers without them get a log entry bound to them instead.
+func loggableFrom(ctx context.Context) Loggable {
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	l := loggerFrom(ctx)
+	if lg, ok := l.(Loggable); ok {
+		return lg
+	}
+
+	return boundLogEntry(l)
+}
+
 /*
 	Setters (Eventful interface)
 */
@@ -47,57 +78,135 @@ func SetLabel(ctx context.Context, key string, value interface{}) Eventful {
 	return eventFromCtx(ctx).SetLabel(key, value)
 }
 
-// With registers a set of fields
+// SetHTTPRequest attaches the HTTP request that the event found in ctx is handling.
+// If no event was found, it will create (ad-hoc) an "Unnamed event" with its severity raised to Warning.
+func SetHTTPRequest(ctx context.Context, req *HTTPRequest) Eventful {
+	return eventFromCtx(ctx).SetHTTPRequest(req)
+}
+
+// With registers a set of fields. The log entry is not bound to any logger yet,
+// it goes through the one carried by the context it gets logged with, see WithLogger.
 func With(key string, value interface{}) Loggable {
 	entry := newLogEntry()
 	entry.fields.add(key, value)
 	return entry
 }
 
+// WithHTTPRequest registers the HTTP request that the log entry refers to.
+func WithHTTPRequest(req *HTTPRequest) Loggable {
+	return With(httpRequestKey, req)
+}
+
+// Log creates a new log entry with the given severity, custom ones included.
+func Log(ctx context.Context, sev Severity, msg string) {
+	loggableFrom(ctx).Log(ctx, sev, msg)
+}
+
+// Logf creates a new log entry with the given severity, custom ones included.
+func Logf(ctx context.Context, sev Severity, msg string, args ...interface{}) {
+	loggableFrom(ctx).Logf(ctx, sev, msg, args...)
+}
+
+// Trace creates a new log entry with the given severity.
+func Trace(ctx context.Context, msg string) {
+	loggableFrom(ctx).Trace(ctx, msg)
+}
+
+// Tracef creates a new log entry with the given severity.
+func Tracef(ctx context.Context, msg string, args ...interface{}) {
+	loggableFrom(ctx).Tracef(ctx, msg, args...)
+}
+
 // Debug creates a new log entry with the given severity.
 func Debug(ctx context.Context, msg string) {
-	newLogEntry().Debug(ctx, msg)
+	loggableFrom(ctx).Debug(ctx, msg)
 }
 
 // Debugf creates a new log entry with the given severity.
 func Debugf(ctx context.Context, msg string, args ...interface{}) {
-	newLogEntry().Debugf(ctx, msg, args...)
+	loggableFrom(ctx).Debugf(ctx, msg, args...)
 }
 
 func Info(ctx context.Context, msg string) {
-	newLogEntry().Info(ctx, msg)
+	loggableFrom(ctx).Info(ctx, msg)
 }
 
 // Infof creates a new log entry with the given severity.
 func Infof(ctx context.Context, msg string, args ...interface{}) {
-	newLogEntry().Infof(ctx, msg, args...)
+	loggableFrom(ctx).Infof(ctx, msg, args...)
+}
+
+// Notice creates a new log entry with the given severity.
+func Notice(ctx context.Context, msg string) {
+	loggableFrom(ctx).Notice(ctx, msg)
+}
+
+// Noticef creates a new log entry with the given severity.
+func Noticef(ctx context.Context, msg string, args ...interface{}) {
+	loggableFrom(ctx).Noticef(ctx, msg, args...)
 }
 
 // Warn creates a new log entry with the given severity.
 func Warn(ctx context.Context, msg string) {
-	newLogEntry().Warn(ctx, msg)
+	loggableFrom(ctx).Warn(ctx, msg)
 }
 
 // Warnf creates a new log entry with the given severity.
 func Warnf(ctx context.Context, msg string, args ...interface{}) {
-	newLogEntry().Warnf(ctx, msg, args...)
+	loggableFrom(ctx).Warnf(ctx, msg, args...)
 }
 
 func Error(ctx context.Context, msg string) {
-	newLogEntry().Error(ctx, msg)
+	loggableFrom(ctx).Error(ctx, msg)
 }
 
 // Errorf creates a new log entry with the given severity.
 func Errorf(ctx context.Context, msg string, args ...interface{}) {
-	newLogEntry().Errorf(ctx, msg, args...)
+	loggableFrom(ctx).Errorf(ctx, msg, args...)
 }
 
 // Fatal creates a new log entry with the given severity.
 func Fatal(ctx context.Context, msg string) {
-	newLogEntry().Fatal(ctx, msg)
+	loggableFrom(ctx).Fatal(ctx, msg)
 }
 
 // Fatalf creates a new log entry with the given severity.
 func Fatalf(ctx context.Context, msg string, args ...interface{}) {
-	newLogEntry().Fatalf(ctx, msg, args...)
+	loggableFrom(ctx).Fatalf(ctx, msg, args...)
+}
+
+// Alert creates a new log entry with the given severity.
+func Alert(ctx context.Context, msg string) {
+	loggableFrom(ctx).Alert(ctx, msg)
+}
+
+// Alertf creates a new log entry with the given severity.
+func Alertf(ctx context.Context, msg string, args ...interface{}) {
+	loggableFrom(ctx).Alertf(ctx, msg, args...)
+}
+
+// Emergency creates a new log entry with the given severity.
+func Emergency(ctx context.Context, msg string) {
+	loggableFrom(ctx).Emergency(ctx, msg)
+}
+
+// Emergencyf creates a new log entry with the given severity.
+func Emergencyf(ctx context.Context, msg string, args ...interface{}) {
+	loggableFrom(ctx).Emergencyf(ctx, msg, args...)
+}
+
+// V returns a Verbose logging only if level is enabled for the caller, e.g.
+//
+//	clogger.V(2).Infof(ctx, "Cache miss for %s", key)
+//
+// The level is checked against the verbosity of the global logger, which the log entry then goes through,
+// whatever the context it gets logged with. Use DefaultLogger.V for another logger.
+// The disabled path costs a single atomic load. It is always disabled unless the global logger is a DefaultLogger.
+func V(level int) Verbose {
+	l, ok := logger().(*DefaultLogger)
+	if !ok {
+		return Verbose{}
+	}
+
+	return l.v(level, 1)
 }
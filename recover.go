@@ -0,0 +1,119 @@
+package clogger
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+type RecoverOption func(c *recoverConfig)
+
+type recoverConfig struct {
+	repanic bool
+}
+
+// WithRepanic panics again with the recovered value, once the panic is logged and the event is ended.
+func WithRepanic() RecoverOption {
+	return func(c *recoverConfig) {
+		c.repanic = true
+	}
+}
+
+// StackFrame is a single, parsed frame of the panicking goroutine's stack.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Recover turns a panic into a Critical log entry holding the panic value and the goroutine's stack.
+// If ctx holds an Event, the entry becomes one of its child logs and the event gets ended.
+// It must be called directly as a deferred function:
+//
+//	defer clogger.Recover(ctx)
+func Recover(ctx context.Context, opts ...RecoverOption) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	handlePanic(ctx, r, opts)
+}
+
+// RecoverAndEnd ends the event, recording a panic (if any) as a Critical child log entry
+// holding the panic value and the goroutine's stack. It must be called directly as a deferred function:
+//
+//	ctx, ev := clogger.NewEvent(ctx, "Processing payment")
+//	defer ev.RecoverAndEnd()
+func (ev *Event) RecoverAndEnd(opts ...RecoverOption) {
+	r := recover()
+	if r == nil {
+		ev.End()
+		return
+	}
+
+	handlePanic(context.WithValue(context.Background(), eventKey, ev), r, opts)
+}
+
+func handlePanic(ctx context.Context, r interface{}, opts []RecoverOption) {
+	c := &recoverConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	entry := newLogEntry()
+	entry.With("panic", fmt.Sprint(r)).
+		With("panic_type", fmt.Sprintf("%T", r)).
+		With("stack", panicStack())
+	entry.log(ctx, SeverityCritical, fmt.Sprintf("Recovered from panic: %v", r)).dispatch()
+
+	if ev, ok := ctx.Value(eventKey).(*Event); ok {
+		ev.End()
+	}
+
+	if c.repanic {
+		panic(r)
+	}
+}
+
+// panicStack returns the stack of the panicking goroutine, starting with the function that panicked.
+// It must be called while panicking, from the deferred function that recovered.
+func panicStack() []StackFrame {
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(1, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	all := make([]StackFrame, 0, n)
+	start := 0
+	for {
+		frame, more := frames.Next()
+		all = append(all, StackFrame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+
+		// Everything up to (and including) the runtime's panic
+		// handling belongs to the recovery, not to the caller.
+		if frame.Function == "runtime.gopanic" {
+			start = len(all)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	// Runtime errors (nil maps, out of range indexes etc.) are raised
+	// by the runtime itself, on behalf of the function that panicked.
+	for start < len(all)-1 && strings.HasPrefix(all[start].Function, "runtime.") {
+		start++
+	}
+
+	return all[start:]
+}
@@ -0,0 +1,66 @@
+package clogger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func panickingHandler(ctx context.Context) {
+	defer Recover(ctx)
+
+	var m map[string]int
+	m["boom"]++
+}
+
+func TestRecover(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	ctx, ev := NewEvent(context.Background(), "Handling request")
+	require.NotPanics(t, func() { panickingHandler(ctx) })
+
+	require.Len(t, sink.Events(), 1, "The event should be ended")
+	assert.Equal(t, SeverityCritical, ev.severity)
+
+	require.Len(t, ev.logs, 1)
+	entry := ev.logs[0]
+	assert.Equal(t, SeverityCritical, entry.severity)
+	assert.Contains(t, entry.fields.retrieve("panic"), "assignment to entry in nil map")
+
+	// Normalized as it gets added, as the encoders see it
+	stack := entry.fields.retrieve("stack").([]interface{})
+	require.NotEmpty(t, stack)
+	frame := stack[0].(map[string]interface{})
+	assert.True(t, strings.HasSuffix(frame["function"].(string), "panickingHandler"), frame["function"])
+	assert.True(t, strings.HasSuffix(frame["file"].(string), "recover_test.go"))
+}
+
+func TestRecoverAndEnd(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	var ev *Event
+	require.PanicsWithValue(t, "boom", func() {
+		_, ev = NewEvent(context.Background(), "Processing payment")
+		defer ev.RecoverAndEnd(WithRepanic())
+
+		panic("boom")
+	})
+
+	require.Len(t, sink.Events(), 1)
+	require.Len(t, ev.logs, 1)
+	assert.Equal(t, "boom", ev.logs[0].fields.retrieve("panic"))
+	assert.Equal(t, "string", ev.logs[0].fields.retrieve("panic_type"))
+
+	// No panic: the event simply ends.
+	_, ev = NewEvent(context.Background(), "Processing payment")
+	func() {
+		defer ev.RecoverAndEnd()
+	}()
+	require.Len(t, sink.Events(), 2)
+	assert.Empty(t, ev.logs)
+}
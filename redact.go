@@ -0,0 +1,349 @@
+package clogger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactAction transforms a sensitive value. Returning false drops the whole field.
+type RedactAction func(value string) (string, bool)
+
+// DropValue drops the field holding the sensitive value.
+func DropValue() RedactAction {
+	return func(string) (string, bool) {
+		return "", false
+	}
+}
+
+// MaskValue replaces every character of the sensitive value with "*", except for the last keep ones.
+func MaskValue(keep int) RedactAction {
+	return func(value string) (string, bool) {
+		runes := []rune(value)
+		if keep < 0 {
+			keep = 0
+		}
+
+		for i := 0; i < len(runes)-keep; i++ {
+			runes[i] = '*'
+		}
+
+		return string(runes), true
+	}
+}
+
+// HashValue replaces the sensitive value with its HMAC-SHA256, keyed with key and hex encoded,
+// keeping equal values correlatable across log entries without revealing them.
+func HashValue(key []byte) RedactAction {
+	return func(value string) (string, bool) {
+		mac := hmac.New(sha256.New, key)
+		_, _ = mac.Write([]byte(value))
+
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)), true
+	}
+}
+
+// TruncateValue keeps the first max characters of the sensitive value, followed by "…".
+func TruncateValue(max int) RedactAction {
+	return func(value string) (string, bool) {
+		if utf8.RuneCountInString(value) <= max {
+			return value, true
+		}
+
+		return string([]rune(value)[:max]) + "…", true
+	}
+}
+
+// Detector finds the sensitive parts of a value, returning their [start, end) indexes.
+type Detector func(value string) [][]int
+
+var (
+	emailPattern       = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardNumberPattern  = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	bearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern         = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]*\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+)
+
+// DetectEmails finds email addresses.
+func DetectEmails() Detector {
+	return DetectPattern(emailPattern)
+}
+
+// DetectCardNumbers finds payment card numbers, i.e. 13 to 19 digits, optionally separated
+// by spaces or dashes, which pass the Luhn check.
+func DetectCardNumbers() Detector {
+	return func(value string) [][]int {
+		var out [][]int
+		for _, loc := range cardNumberPattern.FindAllStringIndex(value, -1) {
+			if luhn(value[loc[0]:loc[1]]) {
+				out = append(out, loc)
+			}
+		}
+
+		return out
+	}
+}
+
+// DetectBearerTokens finds bearer tokens, as found in Authorization headers.
+func DetectBearerTokens() Detector {
+	return DetectPattern(bearerTokenPattern)
+}
+
+// DetectJWTs finds JSON Web Tokens.
+func DetectJWTs() Detector {
+	return DetectPattern(jwtPattern)
+}
+
+// DetectPattern finds the matches of re.
+func DetectPattern(re *regexp.Regexp) Detector {
+	return func(value string) [][]int {
+		return re.FindAllStringIndex(value, -1)
+	}
+}
+
+// luhn reports whether the digits of s pass the Luhn check, ignoring any other character.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+type keyRule struct {
+	match  func(key string) bool
+	action RedactAction
+}
+
+type valueRule struct {
+	detector Detector
+	action   RedactAction
+}
+
+// Redactor removes secrets and PII from log entries and events before they get encoded.
+// Key rules apply to the whole value of the matching fields, nested maps included, while
+// detectors apply to the sensitive parts of any string value. The first matching key rule wins.
+type Redactor struct {
+	keyRules   []keyRule
+	valueRules []valueRule
+}
+
+type RedactorOption func(r *Redactor)
+
+// NewRedactor returns a Redactor applying the given rules, in order.
+// Register it through DefaultLogger.SetRedactor.
+func NewRedactor(opts ...RedactorOption) *Redactor {
+	r := &Redactor{}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// WithRedactedKeys applies action to the fields named after one of names, case-insensitively.
+func WithRedactedKeys(action RedactAction, names ...string) RedactorOption {
+	return func(r *Redactor) {
+		for _, name := range names {
+			name := strings.ToLower(name)
+			r.keyRules = append(r.keyRules, keyRule{
+				match:  func(key string) bool { return strings.ToLower(key) == name },
+				action: action,
+			})
+		}
+	}
+}
+
+// WithRedactedKeyGlobs applies action to the fields whose name matches one of patterns,
+// case-insensitively, e.g. "*password*". See path.Match for the syntax.
+func WithRedactedKeyGlobs(action RedactAction, patterns ...string) RedactorOption {
+	return func(r *Redactor) {
+		for _, pattern := range patterns {
+			pattern := strings.ToLower(pattern)
+			r.keyRules = append(r.keyRules, keyRule{
+				match: func(key string) bool {
+					ok, _ := path.Match(pattern, strings.ToLower(key))
+					return ok
+				},
+				action: action,
+			})
+		}
+	}
+}
+
+// WithRedactedKeyPatterns applies action to the fields whose name matches one of res.
+func WithRedactedKeyPatterns(action RedactAction, res ...*regexp.Regexp) RedactorOption {
+	return func(r *Redactor) {
+		for _, re := range res {
+			r.keyRules = append(r.keyRules, keyRule{match: re.MatchString, action: action})
+		}
+	}
+}
+
+// WithRedactedValues applies action to the parts of the string values found by one of detectors, looking
+// into errors, fmt.Stringer values, slices and maps too. Stringers whose text gets redacted are output as
+// that text. Dropping drops the whole field, or the slice item.
+func WithRedactedValues(action RedactAction, detectors ...Detector) RedactorOption {
+	return func(r *Redactor) {
+		for _, d := range detectors {
+			r.valueRules = append(r.valueRules, valueRule{detector: d, action: action})
+		}
+	}
+}
+
+// redactLogEntry redacts the fields of the log entry, in place.
+func (r *Redactor) redactLogEntry(entry *LogEntry) {
+	entry.fields.rewrite(r.redact)
+}
+
+// redactEvent redacts the fields, error fields and labels of the event, in place.
+// Its child logs get redacted as they are streamed on their own.
+func (r *Redactor) redactEvent(event *Event) {
+	event.fields.rewrite(r.redact)
+	event.errFields.rewrite(r.redact)
+	event.labels.rewrite(r.redact)
+}
+
+// fields returns a redacted copy of the fields of fc, for the outputs other than the logger's own,
+// e.g. spans or propagated labels. A nil Redactor returns them as they are.
+func (r *Redactor) fields(fc *fieldCollection) []field {
+	fields := fc.fields()
+	if r == nil {
+		return fields
+	}
+
+	out := fields[:0]
+	for _, f := range fields {
+		if value, ok := r.redact(f.key, f.value); ok {
+			out = append(out, field{f.key, value})
+		}
+	}
+
+	return out
+}
+
+// redactorOf returns the redactor of l, if any.
+func redactorOf(l Logger) *Redactor {
+	if r, ok := l.(interface{ Redactor() *Redactor }); ok {
+		return r.Redactor()
+	}
+
+	return nil
+}
+
+// redact returns the redacted value of the given field, or false if the field is to be dropped.
+func (r *Redactor) redact(key string, value interface{}) (interface{}, bool) {
+	for _, rule := range r.keyRules {
+		if rule.match(key) {
+			return rule.action(stringValue(value))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.redactString(v)
+	case error:
+		return r.redactString(v.Error())
+	case []string:
+		out := make([]string, 0, len(v))
+		for _, s := range v {
+			if rs, ok := r.redactString(s); ok {
+				out = append(out, rs)
+			}
+		}
+		return out, true
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			if rv, ok := r.redact(k, s); ok {
+				out[k] = stringValue(rv)
+			}
+		}
+		return out, true
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if rv, ok := r.redact(key, item); ok {
+				out = append(out, rv)
+			}
+		}
+		return out, true
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, nested := range v {
+			if rv, ok := r.redact(k, nested); ok {
+				out[k] = rv
+			}
+		}
+		return out, true
+	case fmt.Stringer:
+		if len(r.valueRules) == 0 {
+			return value, true
+		}
+
+		text := v.String()
+		redacted, ok := r.redactString(text)
+		if !ok || redacted != text {
+			return redacted, ok
+		}
+	}
+
+	return value, true
+}
+
+// redactString applies the value rules to the sensitive parts of s.
+func (r *Redactor) redactString(s string) (string, bool) {
+	for _, rule := range r.valueRules {
+		locs := rule.detector(s)
+		if len(locs) == 0 {
+			continue
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, loc := range locs {
+			redacted, ok := rule.action(s[loc[0]:loc[1]])
+			if !ok {
+				return "", false
+			}
+
+			b.WriteString(s[last:loc[0]])
+			b.WriteString(redacted)
+			last = loc[1]
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+
+	return s, true
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,110 @@
+package clogger
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor(
+		WithRedactedKeys(DropValue(), "password"),
+		WithRedactedKeyGlobs(MaskValue(4), "*_token"),
+		WithRedactedKeyPatterns(HashValue([]byte("key")), regexp.MustCompile(`^user_(id|email)$`)),
+		WithRedactedKeys(TruncateValue(5), "comment"),
+		WithRedactedValues(MaskValue(4), DetectCardNumbers()),
+		WithRedactedValues(MaskValue(0), DetectEmails(), DetectBearerTokens(), DetectJWTs()),
+	)
+
+	tests := []struct {
+		key   string
+		value interface{}
+		want  interface{}
+		keep  bool
+	}{
+		{"Password", "hunter2", "", false},
+		{"refresh_token", "abcdef123456", "********3456", true},
+		{"user_id", 42, nil, true},
+		{"comment", "Too long to keep", "Too l…", true},
+		{"payment", "card 4111 1111 1111 1111 declined", "card ***************1111 declined", true},
+		{"not_a_card", "order 4111 1111 1111 1112", "order 4111 1111 1111 1112", true},
+		{"contact", "mail john@example.com", "mail ****************", true},
+		{"error", errors.New("bad header Bearer abc.def"), "bad header **************", true},
+		{"jwt", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig", strings.Repeat("*", 40), true},
+		{"headers", map[string]string{"Password": "x", "X-Ok": "a@b.io"}, map[string]string{"X-Ok": "******"}, true},
+		{"count", 3, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := r.redact(tt.key, tt.value)
+			require.Equal(t, tt.keep, ok)
+			if !ok {
+				return
+			}
+			if tt.key == "user_id" {
+				assert.Regexp(t, `^hmac:[0-9a-f]{64}$`, got)
+				again, _ := r.redact(tt.key, tt.value)
+				assert.Equal(t, got, again, "Hashing should be deterministic")
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDefaultLoggerRedactor(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetRedactor(NewRedactor(
+		WithRedactedKeys(DropValue(), "password"),
+		WithRedactedValues(MaskValue(0), DetectEmails()),
+	))
+	setGlobal(t, l)
+
+	ctx := context.Background()
+	With("password", "hunter2").With("user", "john@example.com").Info(ctx, "Signed in")
+
+	ctx, ev := NewEvent(ctx, "Sign in")
+	ev.Set("password", "hunter2")
+	ev.SetOnErr("user", "john@example.com")
+	ev.SetLabel("contact", "john@example.com")
+	With("password", "hunter2").Info(ctx, "Child")
+	ev.End()
+
+	require.Len(t, sink.entries, 2)
+	assert.Nil(t, sink.entries[0].fields.retrieve("password"))
+	assert.Equal(t, "****************", sink.entries[0].fields.retrieve("user"))
+	assert.Nil(t, sink.entries[1].fields.retrieve("password"))
+	assert.Equal(t, "****************", sink.entries[1].fields.retrieve("contact"), "Labels passed down to child logs should be redacted too")
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Nil(t, event.fields.retrieve("password"))
+	assert.Equal(t, "****************", event.errFields.retrieve("user"))
+	assert.Equal(t, "****************", event.labels.retrieve("contact"))
+}
+
+type mailto string
+
+func (m mailto) String() string { return "mailto:" + string(m) }
+
+func TestRedactorNestedValues(t *testing.T) {
+	r := NewRedactor(WithRedactedValues(MaskValue(0), DetectEmails()))
+
+	got, ok := r.redact("contacts", []interface{}{"john@example.com", 42})
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"****************", 42}, got)
+
+	got, ok = r.redact("contact", mailto("john@example.com"))
+	require.True(t, ok)
+	assert.Equal(t, "mailto:****************", got)
+
+	got, ok = r.redact("contact", mailto("none"))
+	require.True(t, ok)
+	assert.Equal(t, mailto("none"), got, "Stringers left untouched should keep their type")
+}
@@ -0,0 +1,84 @@
+package clogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldsError reports the fields which could not be encoded, e.g. channels, funcs or cyclic values.
+// The encoders replace their values with a "!BADVALUE(reason)" placeholder and still return the output
+// along with this error, which DefaultLogger writes then passes to its ErrorHandler.
+type FieldsError struct {
+	Fields map[string]error
+}
+
+func (e *FieldsError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		msgs = append(msgs, fmt.Sprintf("%q: %v", k, e.Fields[k]))
+	}
+
+	return "clogger: unable to encode fields " + strings.Join(msgs, ", ")
+}
+
+func (e *FieldsError) add(key string, err error) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]error)
+	}
+
+	e.Fields[key] = err
+}
+
+// or returns err if not nil, otherwise e if any field failed, otherwise nil.
+func (e *FieldsError) or(err error) error {
+	if err != nil {
+		return err
+	}
+
+	if len(e.Fields) > 0 {
+		return e
+	}
+
+	return nil
+}
+
+// safeValue encodes its value to JSON on its own, replacing it with a placeholder
+// if it can't be encoded instead of failing the whole entry.
+type safeValue struct {
+	key   string
+	value interface{}
+	errs  *FieldsError
+}
+
+func (v safeValue) MarshalJSON() (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b, err = v.fail(fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	b, err = json.Marshal(v.value)
+	if err != nil {
+		return v.fail(err)
+	}
+
+	return b, nil
+}
+
+func (v safeValue) fail(err error) ([]byte, error) {
+	v.errs.add(v.key, err)
+	return json.Marshal(badValue(err))
+}
+
+// badValue returns the placeholder of a value which could not be encoded.
+func badValue(err error) string {
+	return "!BADVALUE(" + err.Error() + ")"
+}
@@ -0,0 +1,113 @@
+package clogger
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// Sampler decides whether a log entry or an event gets output by the logger.
+type Sampler interface {
+	SampleLogEntry(entry *LogEntry) bool
+	SampleEvent(event *Event) bool
+}
+
+// TraceSampler samples Debug and Info log entries and events along with the trace they belong to,
+// so that a request is either fully logged or not at all, by every service it goes through.
+//
+// Everything belonging to a sampled trace (see the otel_sampled/oc_sampled fields or labels recorded
+// by the trace options) is kept. For unsampled traces, the decision is taken by hashing the trace ID,
+// keeping the given rate of them; the same trace ID always gets the same decision.
+// Anything without a trace ID is kept at the given rate, randomly, events along with their child logs.
+// Severities above Info are never dropped.
+type TraceSampler struct {
+	threshold uint64
+}
+
+// NewTraceSampler returns a TraceSampler keeping the given rate, between 0 and 1, of the unsampled traces.
+func NewTraceSampler(rate float64) *TraceSampler {
+	switch {
+	case rate <= 0:
+		return &TraceSampler{threshold: 0}
+	case rate >= 1:
+		return &TraceSampler{threshold: 1 << 63}
+	default:
+		return &TraceSampler{threshold: uint64(rate * (1 << 63))}
+	}
+}
+
+// Rate returns the rate of the unsampled traces kept.
+func (s *TraceSampler) Rate() float64 {
+	return float64(s.threshold) / (1 << 63)
+}
+
+func (s *TraceSampler) SampleLogEntry(entry *LogEntry) bool {
+	if entry.severity > SeverityInfo {
+		return true
+	}
+
+	return s.sample(entry.sampleKey, entry.traceSampled, entry.fields)
+}
+
+func (s *TraceSampler) SampleEvent(event *Event) bool {
+	if event.severity > SeverityInfo {
+		return true
+	}
+
+	return s.sample(event.sampleKey, event.traceSampled, event.fields, event.labels)
+}
+
+// sample looks for a trace ID in the given field collections, in order. Without one, the decision is taken
+// from the trace of the event the log entry belongs to, if any (see traceSampled and the key derived from its
+// trace ID), then from key, shared by an event and its child logs, if not zero.
+func (s *TraceSampler) sample(key uint64, traceSampled bool, fcs ...*fieldCollection) bool {
+	if traceKey, sampled, ok := traceSampleKey(fcs...); ok {
+		return sampled || s.keep(traceKey)
+	}
+
+	if traceSampled {
+		return true
+	}
+
+	if key != 0 {
+		return s.keep(key)
+	}
+
+	return s.keep(rand.Uint64())
+}
+
+// traceSampleKey looks for a trace ID in the given field collections, in order. It returns its hash,
+// the sampling decision being taken from it, and whether the trace is sampled.
+func traceSampleKey(fcs ...*fieldCollection) (key uint64, sampled bool, ok bool) {
+	for _, fc := range fcs {
+		for _, keys := range [][2]string{{otelTraceID, otelSampled}, {opencensusTraceID, opencensusSampled}} {
+			traceID, ok := fc.retrieve(keys[0]).(string)
+			if !ok || traceID == "" {
+				continue
+			}
+
+			sampled, _ := fc.retrieve(keys[1]).(bool)
+			return hashTraceID(traceID), sampled, true
+		}
+	}
+
+	return 0, false, false
+}
+
+// keep compares the 63 most significant bits of the given (uniformly distributed) value against the threshold,
+// as a threshold keeping everything would not fit an uint64 when comparing all 64 bits.
+func (s *TraceSampler) keep(v uint64) bool {
+	return v>>1 < s.threshold
+}
+
+// hashTraceID hashes the trace ID with FNV-1a, then runs the result through the splitmix64
+// finalizer, as FNV alone spreads similar IDs poorly over the high bits.
+func hashTraceID(traceID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(traceID))
+
+	v := h.Sum64()
+	v = (v ^ (v >> 30)) * 0xbf58476d1ce4e5b9
+	v = (v ^ (v >> 27)) * 0x94d049bb133111eb
+
+	return v ^ (v >> 31)
+}
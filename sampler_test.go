@@ -0,0 +1,108 @@
+package clogger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func tracedEntry(sev Severity, traceID string, sampled bool) *LogEntry {
+	e := newLogEntry()
+	e.severity = sev
+	e.fields.add(otelTraceID, traceID)
+	e.fields.add(otelSampled, sampled)
+
+	return e
+}
+
+func TestTraceSampler(t *testing.T) {
+	s := NewTraceSampler(0.25)
+
+	assert.True(t, s.SampleLogEntry(tracedEntry(SeverityDebug, "4bf92f3577b34da6a3ce929d0e0e4736", true)))
+	assert.True(t, NewTraceSampler(0).SampleLogEntry(tracedEntry(SeverityWarn, "4bf92f3577b34da6a3ce929d0e0e4736", false)))
+	assert.False(t, NewTraceSampler(0).SampleLogEntry(tracedEntry(SeverityInfo, "4bf92f3577b34da6a3ce929d0e0e4736", false)))
+	assert.True(t, NewTraceSampler(1).SampleLogEntry(tracedEntry(SeverityInfo, "4bf92f3577b34da6a3ce929d0e0e4736", false)))
+
+	kept := 0
+	for i := 0; i < 10000; i++ {
+		traceID := fmt.Sprintf("%032x", i)
+
+		keep := s.SampleLogEntry(tracedEntry(SeverityDebug, traceID, false))
+		if keep {
+			kept++
+		}
+
+		// Same trace ID, same decision, whether it's an entry or an event,
+		// which WithOpenCensusTrace records as labels
+		ev := &Event{severity: SeverityInfo, fields: newFieldCollection(), labels: newFieldCollection()}
+		ev.labels.add(opencensusTraceID, traceID)
+		ev.labels.add(opencensusSampled, false)
+		assert.Equal(t, keep, s.SampleEvent(ev))
+	}
+
+	assert.InDelta(t, 2500, kept, 250)
+}
+
+func TestDefaultLoggerSampler(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetSampler(NewTraceSampler(0))
+
+	l.StreamLogEntry(tracedEntry(SeverityInfo, "4bf92f3577b34da6a3ce929d0e0e4736", false))
+	l.StreamLogEntry(tracedEntry(SeverityInfo, "4bf92f3577b34da6a3ce929d0e0e4736", true))
+	l.StreamLogEntry(tracedEntry(SeverityError, "4bf92f3577b34da6a3ce929d0e0e4736", false))
+
+	assert.Len(t, sink.entries, 2)
+}
+
+func TestTraceSamplerUntracedEvents(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetSampler(NewTraceSampler(0.5))
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		ctx, ev := l.NewEvent(context.Background(), "Untraced")
+		Debug(ctx, "First")
+		Info(ctx, "Second")
+		ev.End()
+
+		// Either the whole story or nothing
+		switch len(sink.events) {
+		case kept:
+			assert.Len(t, sink.entries, 2*kept)
+		case kept + 1:
+			kept++
+			assert.Len(t, sink.entries, 2*kept)
+		}
+	}
+
+	assert.InDelta(t, 500, kept, 100)
+}
+
+func TestTraceSamplerTracedEventChildren(t *testing.T) {
+	l, sink := newRecordingLogger()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.NeverSample()))
+	l.SetEventOptions(WithOpenTelemetryTrace(WithStartSpan(), WithTracerProvider(tp)))
+	l.SetSampler(NewTraceSampler(0.5))
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		ctx, ev := l.NewEvent(context.Background(), "Traced")
+		Debug(ctx, "First")
+		Info(ctx, "Second")
+		ev.End()
+
+		// Only the event carries the trace ID, its child logs should still follow its decision
+		switch len(sink.events) {
+		case kept:
+			assert.Len(t, sink.entries, 2*kept)
+		case kept + 1:
+			kept++
+			assert.Len(t, sink.entries, 2*kept)
+		}
+	}
+
+	assert.InDelta(t, 500, kept, 100)
+}
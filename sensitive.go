@@ -0,0 +1,178 @@
+package clogger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// Sensitive is implemented by values which must never be logged raw, e.g. domain types holding credentials.
+// Wherever they are logged, fields (nested ones included) or arguments of the *f methods,
+// they get replaced by what Redacted returns, before reaching any Encoder or Sink.
+type Sensitive interface {
+	Redacted() string
+}
+
+var sensitiveType = reflect.TypeOf((*Sensitive)(nil)).Elem()
+
+// SecretValue wraps a value which must never be logged raw. It renders masked everywhere:
+// in every encoder, through fmt's verbs and when marshalled to JSON or text.
+type SecretValue struct {
+	value       interface{}
+	fingerprint bool
+}
+
+// Secret wraps v, rendering it as "[REDACTED]".
+func Secret(v interface{}) SecretValue {
+	return SecretValue{value: v}
+}
+
+// SecretFingerprint wraps v, rendering it as a short fingerprint, e.g. "sha256:9f86d081",
+// telling whether two logged secrets are the same without revealing them.
+func SecretFingerprint(v interface{}) SecretValue {
+	return SecretValue{value: v, fingerprint: true}
+}
+
+// Value returns the wrapped value.
+func (s SecretValue) Value() interface{} {
+	return s.value
+}
+
+func (s SecretValue) Redacted() string {
+	if !s.fingerprint {
+		return redactedValue
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprint(s.value)))
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
+
+func (s SecretValue) String() string {
+	return s.Redacted()
+}
+
+func (s SecretValue) GoString() string {
+	return s.Redacted()
+}
+
+// Format renders the secret masked whatever the verb, %#v and %q included.
+func (s SecretValue) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(s.Redacted()))
+}
+
+func (s SecretValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.Redacted() + `"`), nil
+}
+
+func (s SecretValue) MarshalText() ([]byte, error) {
+	return []byte(s.Redacted()), nil
+}
+
+// redactedArgs returns args with the ones holding a Sensitive value replaced, copying them only if needed.
+// Sensitive values get replaced by what Redacted returns, while the values nesting them, e.g. structs,
+// get normalized as fields are, see normalizeValue.
+func redactedArgs(args []interface{}) []interface{} {
+	out := args
+	copied := false
+	for i, arg := range args {
+		var redacted interface{}
+		if s, ok := arg.(Sensitive); ok {
+			redacted = redactedString(s)
+		} else if holdsSensitive(arg) {
+			redacted = normalizeField(arg)
+		} else {
+			continue
+		}
+
+		if !copied {
+			out = append([]interface{}(nil), args...)
+			copied = true
+		}
+		out[i] = redacted
+	}
+
+	return out
+}
+
+// holdsSensitive reports whether value is, or holds, a Sensitive value, through a pointer receiver included.
+func holdsSensitive(value interface{}) bool {
+	switch value.(type) {
+	case nil, string, bool, int, int64, float64, error, []byte:
+		return false
+	}
+
+	v := reflect.ValueOf(value)
+	if !needsNormalizing(v.Type()) {
+		return false
+	}
+
+	return findSensitive(v, make(map[visit]bool))
+}
+
+func findSensitive(v reflect.Value, visiting map[visit]bool) bool {
+	if !v.IsValid() {
+		return false
+	}
+
+	t := v.Type()
+	if t.Implements(sensitiveType) || (t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(sensitiveType)) {
+		return true
+	}
+
+	if !needsNormalizing(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+
+		key := visit{ptr: v.Pointer()}
+		if t.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		if visiting[key] {
+			return false
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return findSensitive(v.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if findSensitive(v.Field(i), visiting) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if findSensitive(v.Index(i), visiting) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if findSensitive(iter.Value(), visiting) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// redactedString calls s.Redacted, guarding against nil pointers.
+func redactedString(s Sensitive) string {
+	if v := reflect.ValueOf(s); v.Kind() == reflect.Ptr && v.IsNil() {
+		return redactedValue
+	}
+
+	return s.Redacted()
+}
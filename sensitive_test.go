@@ -0,0 +1,106 @@
+package clogger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAPIKey struct {
+	Key string
+}
+
+func (k *testAPIKey) Redacted() string {
+	return "key-" + k.Key[:2] + "…"
+}
+
+type testCredentials struct {
+	User   string     `log:"user"`
+	APIKey testAPIKey `log:"api_key"`
+	Token  SecretValue
+}
+
+// rawEncoder is a custom Encoder, unaware of secrets.
+type rawEncoder struct{}
+
+func (rawEncoder) EncodeLogEntry(entry *LogEntry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(entry.message)
+	for _, f := range entry.fields.fields() {
+		_, _ = fmt.Fprintf(&b, " %s=%#v", f.key, f.value)
+	}
+	return b.Bytes(), nil
+}
+
+func (rawEncoder) EncodeEvent(event *Event) ([]byte, error) {
+	return []byte(event.message), nil
+}
+
+func TestSecretValue(t *testing.T) {
+	s := Secret("hunter2")
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		assert.Equal(t, redactedValue, fmt.Sprintf(format, s), format)
+	}
+
+	b, err := json.Marshal(map[string]interface{}{"password": s})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password":"[REDACTED]"}`, string(b))
+	assert.Equal(t, "hunter2", s.Value())
+
+	fp := SecretFingerprint("hunter2")
+	assert.Regexp(t, `^sha256:[0-9a-f]{8}$`, fp.Redacted())
+	assert.Equal(t, fp.Redacted(), SecretFingerprint("hunter2").Redacted())
+	assert.NotEqual(t, fp.Redacted(), SecretFingerprint("hunter3").Redacted())
+}
+
+func TestSensitiveEveryEncoder(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	creds := testCredentials{User: "john", APIKey: testAPIKey{Key: "abcdef"}, Token: Secret("t0ken")}
+	With("creds", creds).
+		With("password", Secret("hunter2")).
+		With("key", &testAPIKey{Key: "abcdef"}).
+		Infof(context.Background(), "Signing in with %v and %s", Secret("hunter2"), &testAPIKey{Key: "abcdef"})
+
+	require.Len(t, sink.entries, 1)
+	entry := sink.entries[0]
+	assert.Equal(t, "Signing in with [REDACTED] and key-ab…", entry.message)
+
+	for _, enc := range []Encoder{&JSONEncoder{}, &StackdriverEncoder{}, &TerminalEncoder{}, rawEncoder{}} {
+		b, err := enc.EncodeLogEntry(entry)
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "hunter2")
+		assert.NotContains(t, string(b), "abcdef")
+		assert.NotContains(t, string(b), "t0ken")
+		assert.Contains(t, string(b), "john")
+	}
+}
+
+func TestRedactedArgsPointerReceiver(t *testing.T) {
+	// Passed by value, Redacted being defined on the pointer
+	args := []interface{}{testAPIKey{Key: "abcdef"}, 42}
+
+	got := redactedArgs(args)
+	assert.Equal(t, []interface{}{"key-ab…", 42}, got)
+	assert.Equal(t, testAPIKey{Key: "abcdef"}, args[0], "The args should be copied, not modified")
+}
+
+func TestRedactedArgsNested(t *testing.T) {
+	creds := testCredentials{User: "john", APIKey: testAPIKey{Key: "abcdef"}, Token: Secret("t0ken")}
+	plain := testAddress{City: "Berlin"}
+
+	got := redactedArgs([]interface{}{creds, &creds, []testCredentials{creds}, plain})
+	for _, arg := range got[:3] {
+		s := fmt.Sprintf("%v %+v %#v", arg, arg, arg)
+		assert.NotContains(t, s, "abcdef")
+		assert.NotContains(t, s, "t0ken")
+		assert.Contains(t, s, "john")
+	}
+	assert.Equal(t, plain, got[3], "Values holding nothing sensitive should be kept as they are")
+}
@@ -1,8 +1,15 @@
 package clogger
 
-import "sync"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
 
-// Severity ...
+// Severity ranks log entries and events. The predefined levels follow Cloud Logging's LogSeverity
+// (and syslog's), spaced so that custom ones can be registered in between, see RegisterSeverity.
 type Severity int
 
 type sev struct {
@@ -11,30 +18,163 @@ type sev struct {
 }
 
 const (
-	SeverityDebug Severity = iota
-	SeverityInfo
-	SeverityWarn
-	SeverityError
-	SeverityCritical
+	SeverityTrace     Severity = 50
+	SeverityDebug     Severity = 100
+	SeverityInfo      Severity = 200
+	SeverityNotice    Severity = 300
+	SeverityWarn      Severity = 400
+	SeverityError     Severity = 500
+	SeverityCritical  Severity = 600
+	SeverityAlert     Severity = 700
+	SeverityEmergency Severity = 800
 )
 
 var (
 	sevMap = sev{
 		m: map[Severity]string{
-			0: "DEBUG",
-			1: "INFO",
-			2: "WARN",
-			3: "ERROR",
-			4: "CRITICAL",
+			SeverityTrace:     "TRACE",
+			SeverityDebug:     "DEBUG",
+			SeverityInfo:      "INFO",
+			SeverityNotice:    "NOTICE",
+			SeverityWarn:      "WARN",
+			SeverityError:     "ERROR",
+			SeverityCritical:  "CRITICAL",
+			SeverityAlert:     "ALERT",
+			SeverityEmergency: "EMERGENCY",
 		},
 		mu: &sync.Mutex{},
 	}
+
+	// The predefined levels, from the highest.
+	predefinedSeverities = []Severity{
+		SeverityEmergency, SeverityAlert, SeverityCritical, SeverityError,
+		SeverityWarn, SeverityNotice, SeverityInfo, SeverityDebug, SeverityTrace,
+	}
+
+	// Alternative names accepted by ParseSeverity.
+	severityAliases = map[string]Severity{
+		"WARNING": SeverityWarn,
+		"FATAL":   SeverityCritical,
+	}
 )
 
+// RegisterSeverity names a custom level, e.g. RegisterSeverity(SeverityInfo+50, "AUDIT").
+// Encoders without a matching level map it to the closest predefined one below.
+// Names are case-insensitive and can't be shared by different levels. The predefined levels can't be
+// renamed, nor can a custom level once registered.
+func RegisterSeverity(level Severity, name string) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("clogger: empty severity name for level %d", level)
+	}
+
+	for _, p := range predefinedSeverities {
+		if level == p {
+			return fmt.Errorf("clogger: severity level %d is predefined", level)
+		}
+	}
+
+	sevMap.mu.Lock()
+	defer sevMap.mu.Unlock()
+
+	if n, ok := sevMap.m[level]; ok && n != name {
+		return fmt.Errorf("clogger: severity level %d is already named %q", level, n)
+	}
+
+	if s, ok := severityAliases[name]; ok && s != level {
+		return fmt.Errorf("clogger: severity name %q is already used by level %d", name, s)
+	}
+
+	for s, n := range sevMap.m {
+		if n == name && s != level {
+			return fmt.Errorf("clogger: severity name %q is already used by level %d", name, s)
+		}
+	}
+
+	sevMap.m[level] = name
+
+	return nil
+}
+
+// ParseSeverity returns the level named name, case-insensitively, or given as a number.
+// WARNING and FATAL are accepted for Warn and Critical.
+func ParseSeverity(name string) (Severity, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	sevMap.mu.Lock()
+	defer sevMap.mu.Unlock()
+
+	for s, n := range sevMap.m {
+		if n == name {
+			return s, nil
+		}
+	}
+
+	if s, ok := severityAliases[name]; ok {
+		return s, nil
+	}
+
+	if i, err := strconv.Atoi(name); err == nil {
+		return Severity(i), nil
+	}
+
+	return 0, fmt.Errorf("clogger: unknown severity %q", name)
+}
+
 // String implements the stringer interface.
+// Unnamed levels are returned as numbers.
 func (s Severity) String() string {
 	sevMap.mu.Lock()
 	defer sevMap.mu.Unlock()
 
-	return sevMap.m[s]
+	if name, ok := sevMap.m[s]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(s))
+}
+
+// predefined returns the closest predefined level below or equal to s, SeverityTrace at the least.
+func (s Severity) predefined() Severity {
+	for _, p := range predefinedSeverities {
+		if s >= p {
+			return p
+		}
+	}
+
+	return SeverityTrace
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = parsed
+	return nil
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON accepts both names and numbers.
+func (s *Severity) UnmarshalJSON(b []byte) error {
+	var i int
+	if err := json.Unmarshal(b, &i); err == nil {
+		*s = Severity(i)
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return fmt.Errorf("clogger: invalid severity %s", b)
+	}
+
+	return s.UnmarshalText([]byte(name))
 }
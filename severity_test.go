@@ -0,0 +1,104 @@
+package clogger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeverityOrder(t *testing.T) {
+	levels := []Severity{
+		SeverityTrace, SeverityDebug, SeverityInfo, SeverityNotice, SeverityWarn,
+		SeverityError, SeverityCritical, SeverityAlert, SeverityEmergency,
+	}
+	for i := 1; i < len(levels); i++ {
+		assert.Less(t, int(levels[i-1]), int(levels[i]))
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for name, want := range map[string]Severity{
+		"trace":     SeverityTrace,
+		"INFO":      SeverityInfo,
+		" Notice ":  SeverityNotice,
+		"warning":   SeverityWarn,
+		"WARN":      SeverityWarn,
+		"fatal":     SeverityCritical,
+		"emergency": SeverityEmergency,
+		"250":       Severity(250),
+	} {
+		got, err := ParseSeverity(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := ParseSeverity("loud")
+	assert.Error(t, err)
+}
+
+func TestRegisterSeverity(t *testing.T) {
+	audit := SeverityInfo + 50
+	require.NoError(t, RegisterSeverity(audit, "audit"))
+	defer func() {
+		sevMap.mu.Lock()
+		delete(sevMap.m, audit)
+		sevMap.mu.Unlock()
+	}()
+
+	assert.Equal(t, "AUDIT", audit.String())
+	got, err := ParseSeverity("Audit")
+	require.NoError(t, err)
+	assert.Equal(t, audit, got)
+
+	assert.Error(t, RegisterSeverity(audit+1, "AUDIT"))
+	assert.Error(t, RegisterSeverity(audit+1, "warning"))
+	assert.Error(t, RegisterSeverity(audit+1, ""))
+	assert.Error(t, RegisterSeverity(audit, "REVIEW"), "Registered levels should not be renamed")
+	assert.NoError(t, RegisterSeverity(audit, "AUDIT"))
+
+	assert.Error(t, RegisterSeverity(SeverityWarn, "LOUD"), "Predefined levels should not be renamed")
+	assert.Equal(t, "WARN", SeverityWarn.String())
+	got, err = ParseSeverity("warn")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityWarn, got)
+
+	assert.Equal(t, "INFO", stackdriverSeverity(audit))
+	assert.Equal(t, int32(9), otlpSeverityNumber(audit))
+	assert.Equal(t, "251", (audit + 1).String())
+}
+
+func TestSeverityMarshalling(t *testing.T) {
+	b, err := json.Marshal(map[string]Severity{"level": SeverityNotice})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"level":"NOTICE"}`, string(b))
+
+	var got struct {
+		A Severity `json:"a"`
+		B Severity `json:"b"`
+		C Severity `json:"c"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"a":"warning","b":600,"c":"ALERT"}`), &got))
+	assert.Equal(t, SeverityWarn, got.A)
+	assert.Equal(t, SeverityCritical, got.B)
+	assert.Equal(t, SeverityAlert, got.C)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a":"loud"}`), &got))
+}
+
+func TestStackdriverSeverity(t *testing.T) {
+	for sev, want := range map[Severity]string{
+		SeverityTrace:     "DEBUG",
+		SeverityDebug:     "DEBUG",
+		SeverityInfo:      "INFO",
+		SeverityNotice:    "NOTICE",
+		SeverityWarn:      "WARNING",
+		SeverityError:     "ERROR",
+		SeverityCritical:  "CRITICAL",
+		SeverityAlert:     "ALERT",
+		SeverityEmergency: "EMERGENCY",
+	} {
+		assert.Equal(t, want, stackdriverSeverity(sev))
+	}
+}
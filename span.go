@@ -0,0 +1,108 @@
+package clogger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithOpenTelemetrySpanEvents mirrors every log entry logged under an active (recording) span
+// onto that span, as a span event named after the message, with the entry's fields as attributes.
+// Error and Critical entries are also recorded as errors on the span, setting its status to Error.
+// Only the entries written by a DefaultLogger get mirrored, once redacted, those dropped by its
+// level, sampling or rate limits are left out.
+func WithOpenTelemetrySpanEvents() LogEntryOption {
+	return func(ctx context.Context, entry *LogEntry) {
+		span := trace.SpanFromContext(ctx)
+		if !span.IsRecording() {
+			return
+		}
+
+		entry.onWrite(func() {
+			attrs := spanAttributes("", entry.fields)
+			attrs = append(attrs, attribute.String("severity", entry.severity.String()))
+			span.AddEvent(entry.message, trace.WithAttributes(attrs...))
+
+			if entry.severity >= SeverityError {
+				span.RecordError(errors.New(entry.message), trace.WithAttributes(attrs...))
+				span.SetStatus(codes.Error, entry.message)
+			}
+		})
+	}
+}
+
+// WithOpenTelemetryEventSpan backs every event with its own span, a child of the span active in ctx, if any.
+// The span is passed down through the event's context and it ends along with the event. If written by
+// a DefaultLogger, once redacted, the event's fields and labels (prefixed with "label.") are added to the
+// span as attributes; error fields only if the event's severity was raised over Info. Error and Critical
+// events set the span's status to Error.
+// Register it before WithOpenTelemetryTrace, for the latter to stamp the event with the IDs of this span.
+func WithOpenTelemetryEventSpan(opts ...TraceOption) EventOption {
+	c := newTraceConfig(opts)
+
+	return func(ctx context.Context, event *Event) {
+		var span trace.Span
+		event.ctx, span = c.tracer().Start(ctx, event.message)
+
+		event.onWrite(func() {
+			span.SetAttributes(spanAttributes("", event.fields)...)
+			span.SetAttributes(spanAttributes("label.", event.labels)...)
+			if event.severity > SeverityInfo {
+				span.SetAttributes(spanAttributes("", event.errFields)...)
+			}
+		})
+
+		event.onEnd(func() {
+			span.SetAttributes(attribute.String("severity", event.severity.String()))
+			if event.severity >= SeverityError {
+				span.SetStatus(codes.Error, event.message)
+			}
+
+			span.End()
+		})
+	}
+}
+
+// spanAttributes converts a field collection into span attributes, with their keys prefixed by prefix.
+func spanAttributes(prefix string, fc *fieldCollection) []attribute.KeyValue {
+	fields := fc.fields()
+
+	out := make([]attribute.KeyValue, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, spanAttribute(prefix+f.key, f.value))
+	}
+
+	return out
+}
+
+func spanAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case error:
+		return attribute.String(key, v.Error())
+	case fmt.Stringer:
+		return attribute.Stringer(key, v)
+	}
+
+	if b, err := json.Marshal(value); err == nil {
+		return attribute.String(key, string(b))
+	}
+
+	return attribute.String(key, fmt.Sprint(value))
+}
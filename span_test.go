@@ -0,0 +1,138 @@
+package clogger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func attributeMap(kvs []attribute.KeyValue) map[string]interface{} {
+	out := make(map[string]interface{}, len(kvs))
+	for _, kv := range kvs {
+		out[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return out
+}
+
+func TestWithOpenTelemetrySpans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithOpenTelemetryEventSpan(WithTracerProvider(tp)), WithOpenTelemetryTrace())
+	l.SetLogEntryOptions(WithOpenTelemetrySpanEvents())
+	setGlobal(t, l)
+
+	ctx, ev := NewEvent(context.Background(), "Charging card")
+	ev.Set("amount", 42)
+	ev.SetLabel("tenant_id", "acme")
+	ev.SetOnErr("card", "visa")
+
+	With("attempt", 1).Info(ctx, "Calling the payment provider")
+	With("attempt", 2).Error(ctx, "Payment provider unavailable")
+
+	sc := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, sc.SpanID().String(), ev.fields.retrieve(otelSpanID))
+	ev.End()
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, "Charging card", span.Name())
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	attrs := attributeMap(span.Attributes())
+	assert.Equal(t, int64(42), attrs["amount"])
+	assert.Equal(t, "acme", attrs["label.tenant_id"])
+	assert.Equal(t, "visa", attrs["card"])
+	assert.Equal(t, "ERROR", attrs["severity"])
+
+	// Two span events mirroring the log entries, one for the recorded error.
+	events := span.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "Calling the payment provider", events[0].Name)
+	assert.Equal(t, int64(1), attributeMap(events[0].Attributes)["attempt"])
+	assert.Equal(t, "Payment provider unavailable", events[1].Name)
+	assert.Equal(t, "exception", events[2].Name)
+}
+
+func TestWithOpenTelemetryEventSpanInfo(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithOpenTelemetryEventSpan(WithTracerProvider(tp)))
+	setGlobal(t, l)
+
+	_, ev := NewEvent(context.Background(), "Listing users")
+	ev.SetOnErr("query", "SELECT *")
+	ev.End()
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+	assert.Equal(t, codes.Unset, span.Status().Code)
+	assert.NotContains(t, attributeMap(span.Attributes()), "query")
+}
+
+func TestWithOpenTelemetrySpansRedacted(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	l, _ := newRecordingLogger()
+	l.SetEventOptions(WithOpenTelemetryEventSpan(WithTracerProvider(tp)))
+	l.SetLogEntryOptions(WithOpenTelemetrySpanEvents())
+	l.SetRedactor(NewRedactor(
+		WithRedactedKeys(DropValue(), "password"),
+		WithRedactedValues(MaskValue(0), DetectEmails()),
+	))
+
+	ctx, ev := l.NewEvent(context.Background(), "Sign in")
+	ev.Set("password", "hunter2")
+	ev.SetLabel("contact", "john@example.com")
+	ev.SetOnErr("user", "john@example.com")
+	l.With("password", "hunter2").Error(ctx, "Invalid password")
+	ev.End()
+
+	require.Len(t, sr.Ended(), 1)
+	span := sr.Ended()[0]
+
+	attrs := attributeMap(span.Attributes())
+	assert.NotContains(t, attrs, "password")
+	assert.Equal(t, "****************", attrs["label.contact"])
+	assert.Equal(t, "****************", attrs["user"])
+
+	require.NotEmpty(t, span.Events())
+	for _, event := range span.Events() {
+		assert.NotContains(t, attributeMap(event.Attributes), "password")
+	}
+}
+
+func TestWithOpenTelemetrySpansDropped(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	l, _ := newRecordingLogger()
+	l.SetEventOptions(WithOpenTelemetryEventSpan(WithTracerProvider(tp)))
+	l.SetLogEntryOptions(WithOpenTelemetrySpanEvents())
+	l.SetLevel(SeverityWarn)
+
+	ctx, ev := l.NewEvent(context.Background(), "Listing users")
+	ev.Set("user", "u1")
+	ev.SetLabel("tenant_id", "acme")
+	l.With("query", "SELECT *").Info(ctx, "Querying")
+	ev.End()
+
+	require.Len(t, sr.Ended(), 1, "The span should still be ended")
+	span := sr.Ended()[0]
+	assert.Empty(t, span.Events(), "Dropped log entries should not be mirrored")
+
+	attrs := attributeMap(span.Attributes())
+	assert.NotContains(t, attrs, "user", "Dropped events should not be mirrored")
+	assert.NotContains(t, attrs, "label.tenant_id")
+}
@@ -0,0 +1,333 @@
+package clogger
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Structs passed as field values are converted to maps as they get added, following their `log` struct tags:
+//
+//	type User struct {
+//		ID       string `log:"id"`           // logged as "id"
+//		Password string `log:"-"`            // never logged
+//		Email    string `log:",redact"`      // logged as "Email": "[REDACTED]"
+//		Nickname string `log:",omitempty"`   // skipped when empty
+//		Address  Address `log:",inline"`     // Address' fields logged next to User's
+//	}
+//
+// Fields without a `log` tag fall back to their `json` tag name, then to their Go name, as encoding/json does.
+// Anonymous struct fields are inlined. Values implementing json.Marshaler, encoding.TextMarshaler or error
+// (e.g. time.Time) are left to the encoders, as they are, while Sensitive ones get replaced.
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+
+	// reflect.Type => *structEncoder
+	structEncoders sync.Map
+
+	// reflect.Type => bool
+	normalizedTypes sync.Map
+)
+
+type structEncoder struct {
+	fields []structField
+}
+
+type structField struct {
+	index     []int
+	name      string
+	omitempty bool
+	redact    bool
+	inline    bool
+}
+
+// normalizeField converts value as it gets added to a field collection, so that every output
+// (encoders, sinks and spans alike) sees the same normalized values. A value panicking while
+// being converted, e.g. from its Redacted method, gets replaced with a placeholder.
+func normalizeField(value interface{}) (out interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			out = badValue(fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	return normalizeValue(value)
+}
+
+// normalizeValue converts the structs found in value, nested ones included, to maps.
+// Values holding no structs are returned as they are.
+func normalizeValue(value interface{}) interface{} {
+	if s, ok := value.(Sensitive); ok {
+		return redactedString(s)
+	}
+
+	switch value.(type) {
+	case nil, string, bool, int, int64, float64, error, []byte:
+		return value
+	}
+
+	v := reflect.ValueOf(value)
+	if !needsNormalizing(v.Type()) {
+		return value
+	}
+
+	return normalize(v, make(map[visit]bool))
+}
+
+// visit identifies the pointers, maps and slices being normalized, to detect cycles.
+type visit struct {
+	ptr uintptr
+	len int
+}
+
+var errCyclicValue = errors.New("cyclic value")
+
+func normalize(v reflect.Value, visiting map[visit]bool) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	t := v.Type()
+	if !needsNormalizing(t) {
+		return v.Interface()
+	}
+
+	if t.Implements(sensitiveType) && v.CanInterface() {
+		return redactedString(v.Interface().(Sensitive))
+	}
+
+	// Sensitive through a pointer receiver
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(sensitiveType) {
+		if !v.CanInterface() {
+			return redactedValue
+		}
+
+		p := reflect.New(t)
+		p.Elem().Set(v)
+		return redactedString(p.Interface().(Sensitive))
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+
+		key := visit{ptr: v.Pointer()}
+		if t.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		if visiting[key] {
+			return badValue(errCyclicValue)
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return normalize(v.Elem(), visiting)
+	case reflect.Struct:
+		out := make(map[string]interface{})
+		encoderOf(t).encode(v, out, visiting)
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = normalize(v.Index(i), visiting)
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[mapKey(iter.Key())] = normalize(iter.Value(), visiting)
+		}
+		return out
+	}
+
+	return v.Interface()
+}
+
+// needsNormalizing reports whether values of type t might hold a struct to convert.
+func needsNormalizing(t reflect.Type) bool {
+	if ok, found := normalizedTypes.Load(t); found {
+		return ok.(bool)
+	}
+
+	ok := holdsStruct(t, map[reflect.Type]bool{})
+	normalizedTypes.Store(t, ok)
+
+	return ok
+}
+
+func holdsStruct(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	if t.Implements(sensitiveType) || (t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(sensitiveType)) {
+		return true
+	}
+
+	if implementsMarshaler(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Interface:
+		// Only known once there's a value
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return holdsStruct(t.Elem(), visiting)
+	case reflect.Map:
+		return holdsStruct(t.Elem(), visiting)
+	}
+
+	return false
+}
+
+func implementsMarshaler(t reflect.Type) bool {
+	for _, i := range []reflect.Type{jsonMarshalerType, textMarshalerType, errorType} {
+		if t.Implements(i) || (t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(i)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if b, err := tm.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+
+	b, _ := json.Marshal(k.Interface())
+	return strings.Trim(string(b), `"`)
+}
+
+// encoderOf returns the cached encoder of the struct type t, building it on first use.
+func encoderOf(t reflect.Type) *structEncoder {
+	if enc, ok := structEncoders.Load(t); ok {
+		return enc.(*structEncoder)
+	}
+
+	enc, _ := structEncoders.LoadOrStore(t, newStructEncoder(t))
+	return enc.(*structEncoder)
+}
+
+func newStructEncoder(t reflect.Type) *structEncoder {
+	enc := &structEncoder{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		embedded := sf.Anonymous && sf.Type.Kind() == reflect.Struct
+		if !sf.IsExported() && !embedded {
+			continue
+		}
+
+		tag, hasTag := sf.Tag.Lookup("log")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := parseLogTag(tag)
+		if !hasTag {
+			name, opts = parseLogTag(sf.Tag.Get("json"))
+			if name == "-" {
+				continue
+			}
+		}
+
+		f := structField{index: sf.Index, name: name}
+		for _, opt := range opts {
+			switch opt {
+			case "omitempty":
+				f.omitempty = true
+			case "redact":
+				f.redact = true
+			case "inline":
+				f.inline = true
+			}
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		// As with encoding/json, anonymous struct fields without a name get inlined.
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct && !implementsMarshaler(ft) {
+			f.inline = true
+		}
+
+		if f.inline && (ft.Kind() != reflect.Struct || implementsMarshaler(ft)) {
+			f.inline = false
+		}
+
+		// The exported fields of unexported embedded structs are still reachable, as with encoding/json.
+		if !sf.IsExported() && !f.inline {
+			continue
+		}
+
+		if f.name == "" {
+			f.name = sf.Name
+		}
+
+		enc.fields = append(enc.fields, f)
+	}
+
+	return enc
+}
+
+func parseLogTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+func (enc *structEncoder) encode(v reflect.Value, out map[string]interface{}, visiting map[visit]bool) {
+	for _, f := range enc.fields {
+		fv := v.FieldByIndex(f.index)
+
+		if f.inline {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+
+			encoderOf(fv.Type()).encode(fv, out, visiting)
+			continue
+		}
+
+		if f.omitempty && fv.IsZero() {
+			continue
+		}
+
+		if f.redact {
+			out[f.name] = redactedValue
+			continue
+		}
+
+		out[f.name] = normalize(fv, visiting)
+	}
+}
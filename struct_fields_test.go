@@ -0,0 +1,124 @@
+package clogger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	City    string `log:"city"`
+	Country string `log:"country,omitempty"`
+}
+
+type testAudit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type testUser struct {
+	testAudit
+	ID       string       `log:"id"`
+	Password string       `log:"-"`
+	Email    string       `log:",redact"`
+	Nickname string       `log:",omitempty"`
+	Address  testAddress  `log:",inline"`
+	Friends  []*testUser  `log:"friends,omitempty"`
+	Manager  *testAddress `log:"manager"`
+	internal string
+}
+
+func TestNormalizeValue(t *testing.T) {
+	created := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	u := &testUser{
+		testAudit: testAudit{CreatedAt: created},
+		ID:        "u1",
+		Password:  "hunter2",
+		Email:     "john@example.com",
+		Address:   testAddress{City: "Berlin"},
+		Friends:   []*testUser{{ID: "u2"}},
+		internal:  "secret",
+	}
+
+	got := normalizeValue(u)
+	assert.Equal(t, map[string]interface{}{
+		"created_at": created,
+		"id":         "u1",
+		"Email":      redactedValue,
+		"city":       "Berlin",
+		"friends": []interface{}{map[string]interface{}{
+			"created_at": time.Time{},
+			"id":         "u2",
+			"Email":      redactedValue,
+			"city":       "",
+			"manager":    nil,
+		}},
+		"manager": nil,
+	}, got)
+
+	// Values holding no structs are kept as they are
+	ints := []int{1, 2}
+	assert.Equal(t, ints, normalizeValue(ints))
+	assert.Equal(t, created, normalizeValue(created))
+	assert.Equal(t, map[string]interface{}{"u": map[string]interface{}{"city": "Paris", "country": "FR"}},
+		normalizeValue(map[string]testAddress{"u": {City: "Paris", Country: "FR"}}))
+
+	_, cached := structEncoders.Load(reflect.TypeOf(testUser{}))
+	assert.True(t, cached)
+}
+
+func TestStructFieldsEncoders(t *testing.T) {
+	l, sink := newRecordingLogger()
+	setGlobal(t, l)
+
+	u := testUser{ID: "u1", Password: "hunter2", Email: "john@example.com"}
+	ctx, ev := NewEvent(context.Background(), "Sign in")
+	ev.Set("user", u)
+	ev.SetLabel("user", u)
+	With("user", u).Info(ctx, "Signed in")
+	ev.End()
+
+	require.Len(t, sink.entries, 1)
+	require.Len(t, sink.events, 1)
+
+	for _, enc := range []Encoder{&JSONEncoder{}, &StackdriverEncoder{}, &TerminalEncoder{}} {
+		b, err := enc.EncodeLogEntry(sink.entries[0])
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "hunter2")
+		assert.NotContains(t, string(b), "john@example.com")
+		assert.Contains(t, string(b), `"id":"u1"`)
+
+		b, err = enc.EncodeEvent(sink.events[0])
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "hunter2")
+		assert.NotContains(t, string(b), "john@example.com")
+		assert.Contains(t, string(b), `"id":"u1"`)
+	}
+}
+
+func TestStructFieldsNormalizedOnAdd(t *testing.T) {
+	u := testUser{ID: "u1", Password: "hunter2", Email: "john@example.com"}
+
+	// Whatever the logger, e.g. encoders used on their own
+	entry := newLogEntry()
+	entry.With("user", u)
+	_, ev := newEvent(context.Background(), NewDefaultLogger(), "Sign in")
+	ev.Set("user", u)
+	ev.SetLabel("user", u)
+	ev.SetOnErr("user", u)
+
+	for _, enc := range []Encoder{&JSONEncoder{}, &StackdriverEncoder{}, &TerminalEncoder{}} {
+		b, err := enc.EncodeLogEntry(entry)
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "hunter2")
+		assert.Contains(t, string(b), `"id":"u1"`)
+
+		b, err = enc.EncodeEvent(ev)
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "hunter2")
+		assert.NotContains(t, string(b), "john@example.com")
+	}
+}
@@ -0,0 +1,205 @@
+package clogger
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pkgDir is the directory holding the package's sources,
+// used to tell the caller's frames apart from the package's own.
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+type rateLimit struct {
+	burst  int
+	window time.Duration
+}
+
+// throttle rate limits the log entries per call site and message template, and collapses
+// identical log entries, according to the limits configured for their severity.
+// Suppressed entries are reported through a summary entry, passed to emit once their window is over.
+type throttle struct {
+	mu         sync.Mutex
+	rateLimits map[Severity]rateLimit
+	dedupes    map[Severity]time.Duration
+	windows    map[string]*throttleWindow
+	emit       func(entry *LogEntry)
+}
+
+// throttleWindow tracks the entries sharing the same key, since the first one.
+type throttleWindow struct {
+	since      time.Time
+	count      int
+	suppressed int
+	last       *LogEntry
+}
+
+func newThrottle(emit func(entry *LogEntry)) *throttle {
+	return &throttle{
+		rateLimits: make(map[Severity]rateLimit),
+		dedupes:    make(map[Severity]time.Duration),
+		windows:    make(map[string]*throttleWindow),
+		emit:       emit,
+	}
+}
+
+func (t *throttle) setRateLimit(sev Severity, burst int, window time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if burst <= 0 || window <= 0 {
+		delete(t.rateLimits, sev)
+		return
+	}
+
+	t.rateLimits[sev] = rateLimit{burst: burst, window: window}
+}
+
+// rateLimited reports whether the entries of the given severity are rate limited.
+func (t *throttle) rateLimited(sev Severity) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.rateLimits[sev]
+	return ok
+}
+
+func (t *throttle) setDedupe(sev Severity, window time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if window <= 0 {
+		delete(t.dedupes, sev)
+		return
+	}
+
+	t.dedupes[sev] = window
+}
+
+// allow reports whether the entry should be output.
+func (t *throttle) allow(entry *LogEntry) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if window, ok := t.dedupes[entry.severity]; ok {
+		key := "dedupe|" + dedupeKey(entry)
+		if !t.track(key, entry, 1, window, "repeated %s times since %s") {
+			return false
+		}
+	}
+
+	if limit, ok := t.rateLimits[entry.severity]; ok {
+		caller := entry.caller
+		if caller == "" {
+			caller = callerLocation()
+		}
+
+		key := "rate|" + strconv.Itoa(int(entry.severity)) + "|" + caller + "|" + entry.template
+		if !t.track(key, entry, limit.burst, limit.window, "rate limited, %s more dropped since %s") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// track counts the entry against the window of its key, opening a new window if needed.
+// It reports whether the entry is within the allowed number of entries per window.
+func (t *throttle) track(key string, entry *LogEntry, allowed int, window time.Duration, summary string) bool {
+	w, ok := t.windows[key]
+	if !ok {
+		w = &throttleWindow{since: time.Now()}
+		t.windows[key] = w
+		time.AfterFunc(window, func() { t.close(key, summary) })
+	}
+
+	w.count++
+	if w.count <= allowed {
+		return true
+	}
+
+	w.suppressed++
+	w.last = entry
+
+	return false
+}
+
+// close ends the window of the given key, emitting a summary entry if anything was suppressed within it.
+func (t *throttle) close(key, summary string) {
+	t.mu.Lock()
+	w := t.windows[key]
+	delete(t.windows, key)
+	t.mu.Unlock()
+
+	if w == nil || w.suppressed == 0 {
+		return
+	}
+
+	entry := newLogEntry()
+	entry.severity = w.last.severity
+	entry.template = w.last.template
+	entry.name = w.last.name
+	entry.caller = w.last.caller
+	entry.message = w.last.message + " (" + fmt.Sprintf(summary, formatCount(w.suppressed), w.since.Format(time.RFC3339)) + ")"
+	entry.fields.merge(w.last.fields)
+	entry.fields.add("suppressed", w.suppressed)
+	entry.fields.add("suppressed_since", w.since)
+
+	t.emit(entry)
+}
+
+// dedupeKey identifies identical log entries: same severity, message and fields, in key order.
+func dedupeKey(entry *LogEntry) string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(int(entry.severity)))
+	b.WriteString("|")
+	b.WriteString(entry.message)
+	for _, f := range entry.fields.fields() {
+		_, _ = fmt.Fprintf(&b, "|%s=%v", f.key, f.value)
+	}
+
+	return b.String()
+}
+
+// callerLocation returns the "file:line" of the first frame outside of the package, i.e. the log call site.
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != pkgDir || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
+
+// formatCount formats n with thousands separators, e.g. 4,812.
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
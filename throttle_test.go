@@ -0,0 +1,141 @@
+package clogger
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultLoggerDedupe(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetDedupe(SeverityError, 50*time.Millisecond)
+	setGlobal(t, l)
+
+	ctx := context.Background()
+	for i := 0; i < 1001; i++ {
+		With("dependency", "billing").Errorf(ctx, "Dependency %s is down", "billing")
+	}
+	With("dependency", "payments").Errorf(ctx, "Dependency %s is down", "payments")
+	Warn(ctx, "Not deduplicated")
+	Warn(ctx, "Not deduplicated")
+
+	sink.mu.Lock()
+	assert.Len(t, sink.entries, 4)
+	sink.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.entries) == 5
+	}, time.Second, 10*time.Millisecond)
+
+	summary := sink.entries[4]
+	assert.Equal(t, SeverityError, summary.severity)
+	assert.True(t, strings.HasPrefix(summary.message, "Dependency billing is down (repeated 1,000 times since "), summary.message)
+	assert.Equal(t, "billing", summary.fields.retrieve("dependency"))
+	assert.Equal(t, 1000, summary.fields.retrieve("suppressed"))
+}
+
+func TestDefaultLoggerRateLimit(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetRateLimit(SeverityError, 2, 50*time.Millisecond)
+	setGlobal(t, l)
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		// Same call site and template, different messages
+		Errorf(ctx, "Attempt %d failed", i)
+	}
+	Errorf(ctx, "Attempt %d failed", 10) // Another call site
+	Error(ctx, "Other template")
+
+	sink.mu.Lock()
+	assert.Len(t, sink.entries, 4)
+	sink.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.entries) == 5
+	}, time.Second, 10*time.Millisecond)
+
+	summary := sink.entries[4]
+	assert.True(t, strings.HasPrefix(summary.message, "Attempt 9 failed (rate limited, 8 more dropped since "), summary.message)
+	assert.Equal(t, 8, summary.fields.retrieve("suppressed"))
+}
+
+func TestDedupeKeyFieldOrder(t *testing.T) {
+	entry := func() *LogEntry {
+		e := newLogEntry()
+		for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			e.fields.add(key, key+"_value")
+		}
+		e.message = "Same"
+
+		return e
+	}
+
+	want := dedupeKey(entry())
+	for i := 0; i < 100; i++ {
+		require.Equal(t, want, dedupeKey(entry()))
+	}
+}
+
+func TestRateLimitChildLogs(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetRateLimit(SeverityError, 1, time.Hour)
+
+	ctx, ev := l.NewEvent(context.Background(), "event")
+	Errorf(ctx, "Failed %d", 1)
+	Errorf(ctx, "Failed %d", 2) // Another call site, same template
+	ev.End()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	assert.Len(t, sink.entries, 2, "Child logs should be rate limited per call site, not per Event.End")
+}
+
+func TestThrottleConcurrent(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		emitted int
+	)
+	th := newThrottle(func(*LogEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		emitted++
+	})
+	th.setDedupe(SeverityInfo, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				e := newLogEntry()
+				e.severity = SeverityInfo
+				e.message = "Same"
+				if th.allow(e) {
+					mu.Lock()
+					emitted++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, emitted)
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1000: "1,000", 4812: "4,812", 1234567: "1,234,567", -4812: "-4,812"} {
+		assert.Equal(t, want, formatCount(n))
+	}
+}
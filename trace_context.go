@@ -0,0 +1,191 @@
+package clogger
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	traceparentHeader       = "traceparent"
+	tracestateHeader        = "tracestate"
+	cloudTraceContextHeader = "X-Cloud-Trace-Context"
+)
+
+var (
+	errInvalidTraceparent       = errors.New("clogger: invalid traceparent header")
+	errInvalidCloudTraceContext = errors.New("clogger: invalid X-Cloud-Trace-Context header")
+)
+
+// TraceContext is the trace context received from upstream, parsed from the request headers
+// without the need of a tracing SDK. IDs are lowercase hex encoded.
+type TraceContext struct {
+	TraceID    string
+	SpanID     string
+	Sampled    bool
+	TraceState string
+}
+
+// ParseTraceparent parses the W3C traceparent and tracestate headers.
+// https://www.w3.org/TR/trace-context/#traceparent-header
+func ParseTraceparent(traceparent, tracestate string) (TraceContext, error) {
+	// version "-" trace-id "-" parent-id "-" trace-flags
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, errInvalidTraceparent
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || !isHex(version) {
+		return TraceContext{}, errInvalidTraceparent
+	}
+
+	// Version 00 defines exactly 4 parts, future versions might append more.
+	if version == "00" && len(parts) != 4 {
+		return TraceContext{}, errInvalidTraceparent
+	}
+
+	if !isValidID(traceID, 32) || !isValidID(spanID, 16) || len(flags) != 2 || !isHex(flags) {
+		return TraceContext{}, errInvalidTraceparent
+	}
+
+	f, _ := strconv.ParseUint(flags, 16, 8)
+
+	return TraceContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		Sampled:    f&0x01 == 0x01,
+		TraceState: strings.TrimSpace(tracestate),
+	}, nil
+}
+
+// ParseCloudTraceContext parses GCP's X-Cloud-Trace-Context header, "TRACE_ID/SPAN_ID;o=TRACE_TRUE",
+// converting the decimal span ID to its hex form.
+// https://cloud.google.com/trace/docs/setup#force-trace
+func ParseCloudTraceContext(header string) (TraceContext, error) {
+	header = strings.TrimSpace(header)
+
+	traceID, rest := header, ""
+	if i := strings.Index(header, "/"); i >= 0 {
+		traceID, rest = header[:i], header[i+1:]
+	}
+
+	traceID = strings.ToLower(traceID)
+	if !isValidID(traceID, 32) {
+		return TraceContext{}, errInvalidCloudTraceContext
+	}
+
+	tc := TraceContext{TraceID: traceID}
+	if rest == "" {
+		return tc, nil
+	}
+
+	spanID, options := rest, ""
+	if i := strings.Index(rest, ";"); i >= 0 {
+		spanID, options = rest[:i], rest[i+1:]
+	}
+
+	if spanID != "" {
+		id, err := strconv.ParseUint(spanID, 10, 64)
+		if err != nil || id == 0 {
+			return TraceContext{}, errInvalidCloudTraceContext
+		}
+		tc.SpanID = fmt.Sprintf("%016x", id)
+	}
+
+	tc.Sampled = options == "o=1"
+
+	return tc, nil
+}
+
+// TraceContextFromHeaders extracts the trace context from the W3C traceparent header,
+// falling back to GCP's X-Cloud-Trace-Context. It reports whether a valid one was found.
+func TraceContextFromHeaders(h http.Header) (TraceContext, bool) {
+	if tp := h.Get(traceparentHeader); tp != "" {
+		if tc, err := ParseTraceparent(tp, h.Get(tracestateHeader)); err == nil {
+			return tc, true
+		}
+	}
+
+	if ctc := h.Get(cloudTraceContextHeader); ctc != "" {
+		if tc, err := ParseCloudTraceContext(ctc); err == nil {
+			return tc, true
+		}
+	}
+
+	return TraceContext{}, false
+}
+
+// ContextWithTraceContext returns a copy of ctx holding tc.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey, tc)
+}
+
+// ContextWithTraceHeaders returns a copy of ctx holding the trace context found in h, if any.
+// HTTPMiddleware calls it for each and every request.
+func ContextWithTraceHeaders(ctx context.Context, h http.Header) context.Context {
+	tc, ok := TraceContextFromHeaders(h)
+	if !ok {
+		return ctx
+	}
+
+	return ContextWithTraceContext(ctx, tc)
+}
+
+// TraceContextFromContext returns the trace context held by ctx, if any.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey).(TraceContext)
+	return tc, ok
+}
+
+// WithTraceContext stamps the event with the trace context found in ctx (see ContextWithTraceHeaders),
+// using the same keys as WithOpenTelemetryTrace. Use one or the other.
+func WithTraceContext() EventOption {
+	return func(ctx context.Context, event *Event) {
+		tc, ok := TraceContextFromContext(ctx)
+		if !ok {
+			return
+		}
+
+		event.fields.add(otelTraceID, tc.TraceID)
+		if tc.SpanID != "" {
+			event.fields.add(otelSpanID, tc.SpanID)
+		}
+		event.fields.add(otelSampled, tc.Sampled)
+	}
+}
+
+// WithTraceContextSpan stamps the log entry with the trace context found in ctx (see ContextWithTraceHeaders),
+// using the same keys as WithOpenTelemetrySpan. Use one or the other.
+func WithTraceContextSpan() LogEntryOption {
+	return func(ctx context.Context, entry *LogEntry) {
+		tc, ok := TraceContextFromContext(ctx)
+		if !ok {
+			return
+		}
+
+		entry.fields.add(otelTraceID, tc.TraceID)
+		if tc.SpanID != "" {
+			entry.fields.add(otelSpanID, tc.SpanID)
+		}
+		entry.fields.add(otelSampled, tc.Sampled)
+	}
+}
+
+// isValidID reports whether id is a lowercase hex encoded ID of the given length, not made of zeroes only.
+func isValidID(id string, length int) bool {
+	if len(id) != length || strings.ToLower(id) != id || !isHex(id) {
+		return false
+	}
+
+	return strings.Trim(id, "0") != ""
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
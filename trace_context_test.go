@@ -0,0 +1,149 @@
+package clogger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		want        TraceContext
+		wantErr     bool
+	}{
+		{
+			name:        "sampled",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:        TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true, TraceState: "congo=t61rcWkgMzE"},
+		},
+		{
+			name:        "not sampled",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			want:        TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", TraceState: "congo=t61rcWkgMzE"},
+		},
+		{
+			name:        "future version with extra parts",
+			traceparent: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			want:        TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true, TraceState: "congo=t61rcWkgMzE"},
+		},
+		{name: "extra parts", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantErr: true},
+		{name: "invalid version", traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "zero trace id", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "zero span id", traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", wantErr: true},
+		{name: "uppercase", traceparent: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "short", traceparent: "00-4bf92f3577b34da6-00f067aa0ba902b7-01", wantErr: true},
+		{name: "garbage", traceparent: "not a traceparent", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTraceparent(tt.traceparent, "congo=t61rcWkgMzE")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseCloudTraceContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    TraceContext
+		wantErr bool
+	}{
+		{
+			name:   "sampled",
+			header: "105445aa7843bc8bf206b12000100000/1;o=1",
+			want:   TraceContext{TraceID: "105445aa7843bc8bf206b12000100000", SpanID: "0000000000000001", Sampled: true},
+		},
+		{
+			name:   "decimal span id",
+			header: "105445aa7843bc8bf206b12000100000/12345678901234567890;o=0",
+			want:   TraceContext{TraceID: "105445aa7843bc8bf206b12000100000", SpanID: "ab54a98ceb1f0ad2"},
+		},
+		{
+			name:   "trace id only",
+			header: "105445AA7843BC8BF206B12000100000",
+			want:   TraceContext{TraceID: "105445aa7843bc8bf206b12000100000"},
+		},
+		{name: "hex span id", header: "105445aa7843bc8bf206b12000100000/00f067aa0ba902b7;o=1", wantErr: true},
+		{name: "span id overflow", header: "105445aa7843bc8bf206b12000100000/18446744073709551616", wantErr: true},
+		{name: "invalid trace id", header: "xyz/1;o=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCloudTraceContext(tt.header)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTraceContextFromHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set(cloudTraceContextHeader, "105445aa7843bc8bf206b12000100000/1;o=1")
+	tc, ok := TraceContextFromHeaders(h)
+	require.True(t, ok)
+	assert.Equal(t, "105445aa7843bc8bf206b12000100000", tc.TraceID)
+
+	// traceparent takes precedence
+	h.Set(traceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	tc, ok = TraceContextFromHeaders(h)
+	require.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tc.TraceID)
+
+	_, ok = TraceContextFromHeaders(http.Header{})
+	assert.False(t, ok)
+}
+
+func TestWithTraceContextStackdriver(t *testing.T) {
+	l := NewDefaultLogger()
+	l.SetEventOptions(WithTraceContext())
+	l.SetLogEntryOptions(WithTraceContextSpan())
+	setGlobal(t, l)
+
+	var ev *Event
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ev = r.Context().Value(eventKey).(*Event)
+		Info(r.Context(), "Handling")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(cloudTraceContextHeader, "105445aa7843bc8bf206b12000100000/1;o=1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	require.NotNil(t, ev)
+
+	for _, encode := range []func() ([]byte, error){
+		func() ([]byte, error) { return (&StackdriverEncoder{}).EncodeEvent(ev) },
+		func() ([]byte, error) { return (&StackdriverEncoder{}).EncodeLogEntry(ev.logs[0]) },
+	} {
+		b, err := encode()
+		require.NoError(t, err)
+
+		out := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(b, &out))
+		assert.Equal(t, stackdriverTrace("105445aa7843bc8bf206b12000100000"), out["logging.googleapis.com/trace"])
+		assert.Equal(t, "0000000000000001", out["logging.googleapis.com/spanId"])
+		assert.Equal(t, true, out["logging.googleapis.com/trace_sampled"])
+	}
+
+	// Nothing to stamp without a trace context
+	_, ev = NewEvent(context.Background(), "Untraced")
+	assert.Nil(t, ev.fields.retrieve(otelTraceID))
+}
@@ -0,0 +1,220 @@
+package clogger
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	redactedValue = "[REDACTED]"
+)
+
+var (
+	// Headers that are never written out as they are, regardless of the configured ones.
+	defaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+	// Statuses that signal a transient failure of the downstream service.
+	retryableStatuses = map[int]bool{
+		http.StatusBadGateway:         true,
+		http.StatusServiceUnavailable: true,
+		http.StatusGatewayTimeout:     true,
+	}
+)
+
+type TransportOption func(t *transport)
+
+// WithTransportRedactedHeaders registers request headers whose values
+// get replaced with a placeholder in the logged output.
+func WithTransportRedactedHeaders(headers ...string) TransportOption {
+	return func(t *transport) {
+		for _, h := range headers {
+			t.redacted[http.CanonicalHeaderKey(h)] = struct{}{}
+		}
+	}
+}
+
+// WithTransportRetries retries idempotent requests up to max times, waiting backoff
+// (doubled after each attempt) in between, on network errors and on 502, 503 and 504 responses.
+func WithTransportRetries(max int, backoff time.Duration) TransportOption {
+	return func(t *transport) {
+		t.maxRetries = max
+		t.backoff = backoff
+	}
+}
+
+// WithTransportPropagator sets the propagator used to inject the trace context
+// into the outgoing request headers. Defaults to the globally registered one.
+func WithTransportPropagator(p propagation.TextMapPropagator) TransportOption {
+	return func(t *transport) {
+		t.propagator = p
+	}
+}
+
+// WithTransportBaggage sends the allowed labels of the event found in the request's context
+// downstream, through the W3C baggage header.
+func WithTransportBaggage(b BaggageLabels) TransportOption {
+	return func(t *transport) {
+		t.baggage = &b
+	}
+}
+
+type transport struct {
+	base       http.RoundTripper
+	redacted   map[string]struct{}
+	maxRetries int
+	backoff    time.Duration
+	propagator propagation.TextMapPropagator
+	baggage    *BaggageLabels
+}
+
+// Transport wraps base (http.DefaultTransport if nil) into a http.RoundTripper that logs every outbound call.
+// Each call results in a log entry which, if the request's context holds an Event, becomes one of its child logs.
+// It records the method, host, status, latency and retries, and injects the trace context headers downstream.
+func Transport(base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t := &transport{
+		base:     base,
+		redacted: make(map[string]struct{}),
+	}
+	for _, h := range defaultRedactedHeaders {
+		t.redacted[h] = struct{}{}
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	start := time.Now()
+
+	// A RoundTripper must not modify the given request.
+	req := r.Clone(ctx)
+	propagator := t.propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if t.baggage != nil {
+		if b := t.baggage.header(ctx, req.Header.Get(baggageHeader)); b != "" {
+			req.Header.Set(baggageHeader, b)
+		}
+	}
+
+	var (
+		resp    *http.Response
+		err     error
+		retries int
+	)
+attempts:
+	for {
+		resp, err = t.base.RoundTrip(req)
+		if retries >= t.maxRetries || !t.retryable(req, resp, err) {
+			break
+		}
+
+		wait := t.backoff << uint(retries)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			resp, err = nil, ctx.Err()
+			break attempts
+		case <-time.After(wait):
+		}
+
+		retries++
+		if req, err = rewind(req); err != nil {
+			resp = nil
+			break
+		}
+	}
+
+	entry := newLogEntry()
+	entry.With("http_method", req.Method).
+		With("http_host", req.URL.Host).
+		With("latency", time.Since(start).String()).
+		With("retries", retries).
+		With("request_headers", t.headers(req.Header))
+
+	var sev Severity
+	if err != nil {
+		sev = SeverityError
+		entry.With("error", err.Error())
+	} else {
+		sev = severityFromHTTPStatus(resp.StatusCode)
+		entry.With("http_status", resp.StatusCode)
+	}
+
+	entry.log(ctx, sev, fmt.Sprintf("%s %s%s", req.Method, req.URL.Host, req.URL.Path)).dispatch()
+
+	return resp, err
+}
+
+// retryable reports whether the request can, and should, be sent again.
+func (t *transport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+	default:
+		return false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	return retryableStatuses[resp.StatusCode]
+}
+
+// headers returns a flattened copy of h, with the values of sensitive headers redacted.
+func (t *transport) headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if _, ok := t.redacted[http.CanonicalHeaderKey(k)]; ok {
+			out[k] = redactedValue
+			continue
+		}
+
+		if len(v) > 0 {
+			out[k] = v[0]
+		}
+	}
+
+	return out
+}
+
+// rewind prepares req to be sent once again, by resetting its body.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return req, err
+	}
+
+	rr := req.Clone(req.Context())
+	rr.Body = body
+	return rr, nil
+}
@@ -0,0 +1,95 @@
+package clogger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTransport(t *testing.T) {
+	setGlobal(t, noopLogger)
+
+	var (
+		calls       int32
+		traceparent atomic.Value
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent.Store(r.Header.Get("traceparent"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{
+		Transport: Transport(nil,
+			WithTransportRetries(2, time.Millisecond),
+			WithTransportRedactedHeaders("X-Api-Key"),
+			WithTransportPropagator(propagation.TraceContext{}),
+		),
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	ctx, ev := NewEvent(ctx, "Outbound call")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/users", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Api-Key", "secret")
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	ev.End()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", traceparent.Load())
+	assert.Empty(t, req.Header.Get("traceparent"), "The caller's request should not be modified")
+
+	require.Len(t, ev.logs, 1)
+	entry := ev.logs[0]
+	assert.Equal(t, SeverityInfo, entry.severity)
+	assert.Equal(t, http.MethodGet, entry.fields.retrieve("http_method"))
+	assert.Equal(t, http.StatusOK, entry.fields.retrieve("http_status"))
+	assert.Equal(t, 1, entry.fields.retrieve("retries"))
+
+	headers := entry.fields.retrieve("request_headers").(map[string]string)
+	assert.Equal(t, redactedValue, headers["X-Api-Key"])
+	assert.Equal(t, redactedValue, headers["Authorization"])
+}
+
+func TestTransportError(t *testing.T) {
+	setGlobal(t, noopLogger)
+
+	client := &http.Client{Transport: Transport(nil)}
+	ctx, ev := NewEvent(context.Background(), "Outbound call")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1:0", nil)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	require.Error(t, err)
+	ev.End()
+
+	require.Len(t, ev.logs, 1)
+	assert.Equal(t, SeverityError, ev.logs[0].severity)
+	assert.NotEmpty(t, ev.logs[0].fields.retrieve("error"))
+	assert.Equal(t, SeverityError, ev.severity)
+}
@@ -0,0 +1,252 @@
+package clogger
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const verbosityKey = "v"
+
+// Verbose gates log entries behind a verbosity level, finer grained than the severities, see V.
+type Verbose struct {
+	// Nil when disabled
+	entry *LogEntry
+}
+
+// Enabled reports whether the verbosity level is enabled, e.g. to skip expensive computations.
+func (v Verbose) Enabled() bool {
+	return v.entry != nil
+}
+
+// With registers a field, if the verbosity level is enabled.
+func (v Verbose) With(key string, value interface{}) Verbose {
+	if v.entry != nil {
+		v.entry.With(key, value)
+	}
+
+	return v
+}
+
+// Debug ...
+func (v Verbose) Debug(ctx context.Context, message string) {
+	if v.entry != nil {
+		v.entry.Debug(ctx, message)
+	}
+}
+
+// Debugf ...
+func (v Verbose) Debugf(ctx context.Context, message string, args ...interface{}) {
+	if v.entry != nil {
+		v.entry.Debugf(ctx, message, args...)
+	}
+}
+
+// Info ...
+func (v Verbose) Info(ctx context.Context, message string) {
+	if v.entry != nil {
+		v.entry.Info(ctx, message)
+	}
+}
+
+// Infof ...
+func (v Verbose) Infof(ctx context.Context, message string, args ...interface{}) {
+	if v.entry != nil {
+		v.entry.Infof(ctx, message, args...)
+	}
+}
+
+// newVerbose binds the log entry to l, if not nil.
+func newVerbose(l Logger, enabled bool, level int) Verbose {
+	if !enabled {
+		return Verbose{}
+	}
+
+	entry := newLogEntry()
+	entry.logger = l
+	entry.fields.add(verbosityKey, level)
+
+	return Verbose{entry: entry}
+}
+
+// vmodulePattern overrides the verbosity of the files it matches.
+type vmodulePattern struct {
+	pattern string
+	level   int32
+}
+
+// verbosity holds the global verbosity level along with its per-file overrides.
+type verbosity struct {
+	// The highest level enabled anywhere, global or overridden.
+	// Anything above is disabled at the cost of a single atomic load.
+	max   int32
+	level int32
+
+	mu      sync.Mutex
+	vmodule []vmodulePattern
+
+	// The verbosity level resolved per call site: *sync.Map of uintptr => int32.
+	// Replaced whenever the overrides change.
+	cache atomic.Value
+}
+
+func newVerbosity() *verbosity {
+	v := &verbosity{}
+	v.cache.Store(&sync.Map{})
+
+	return v
+}
+
+// enabled reports whether level is enabled for the caller, skip frames above.
+func (v *verbosity) enabled(level int, skip int) bool {
+	if int32(level) > atomic.LoadInt32(&v.max) {
+		return false
+	}
+
+	if int32(level) <= atomic.LoadInt32(&v.level) {
+		return true
+	}
+
+	return int32(level) <= v.callerLevel(skip+1)
+}
+
+// callerLevel resolves the verbosity level of the caller, skip frames above, from its file.
+func (v *verbosity) callerLevel(skip int) int32 {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return atomic.LoadInt32(&v.level)
+	}
+
+	cache := v.cache.Load().(*sync.Map)
+	if level, ok := cache.Load(pcs[0]); ok {
+		return level.(int32)
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+
+	v.mu.Lock()
+	level := atomic.LoadInt32(&v.level)
+	for _, p := range v.vmodule {
+		if matchVModule(p.pattern, frame.File) {
+			level = p.level
+			break
+		}
+	}
+	v.mu.Unlock()
+
+	cache.Store(pcs[0], level)
+
+	return level
+}
+
+func (v *verbosity) setLevel(level int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	atomic.StoreInt32(&v.level, int32(level))
+	v.reset()
+}
+
+func (v *verbosity) setVModule(spec string) error {
+	patterns, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.vmodule = patterns
+	v.reset()
+
+	return nil
+}
+
+// set sets both the global level and the overrides at once.
+func (v *verbosity) set(level int, patterns []vmodulePattern) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	atomic.StoreInt32(&v.level, int32(level))
+	v.vmodule = patterns
+	v.reset()
+}
+
+func (v *verbosity) vmoduleSpec() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	parts := make([]string, 0, len(v.vmodule))
+	for _, p := range v.vmodule {
+		parts = append(parts, p.pattern+"="+strconv.Itoa(int(p.level)))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// reset recomputes the highest enabled level and drops the resolved call sites. v.mu must be held.
+func (v *verbosity) reset() {
+	max := atomic.LoadInt32(&v.level)
+	for _, p := range v.vmodule {
+		if p.level > max {
+			max = p.level
+		}
+	}
+
+	atomic.StoreInt32(&v.max, max)
+	v.cache.Store(&sync.Map{})
+}
+
+// parseVModule parses a comma separated list of pattern=level, e.g. "server=2,internal/db/*=3".
+func parseVModule(spec string) ([]vmodulePattern, error) {
+	var patterns []vmodulePattern
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("clogger: invalid vmodule %q, expecting pattern=level", part)
+		}
+
+		level, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("clogger: invalid vmodule level in %q: %w", part, err)
+		}
+
+		if _, err := path.Match(kv[0], ""); err != nil {
+			return nil, fmt.Errorf("clogger: invalid vmodule pattern in %q: %w", part, err)
+		}
+
+		patterns = append(patterns, vmodulePattern{pattern: kv[0], level: int32(level)})
+	}
+
+	return patterns, nil
+}
+
+// matchVModule matches the pattern against the file. Patterns without a "/" match the file's name
+// without its ".go" extension, e.g. "server" or "handler_*"; the others match the trailing elements
+// of its path, e.g. "internal/db/*" for every file of the internal/db package.
+func matchVModule(pattern, file string) bool {
+	file = strings.TrimSuffix(file, ".go")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+
+	elems := strings.Split(file, "/")
+	n := strings.Count(pattern, "/") + 1
+	if len(elems) < n {
+		return false
+	}
+
+	ok, _ := path.Match(pattern, strings.Join(elems[len(elems)-n:], "/"))
+	return ok
+}
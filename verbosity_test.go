@@ -0,0 +1,89 @@
+package clogger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestV(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetVerbosity(1)
+	setGlobal(t, l)
+
+	ctx := context.Background()
+	V(1).With("key", "value").Info(ctx, "Enabled")
+	V(2).Info(ctx, "Disabled")
+	l.V(1).Debugf(ctx, "Enabled %d", 2)
+
+	require.Len(t, sink.entries, 2)
+	assert.Equal(t, 1, sink.entries[0].fields.retrieve(verbosityKey))
+	assert.Equal(t, "value", sink.entries[0].fields.retrieve("key"))
+	assert.Equal(t, SeverityDebug, sink.entries[1].severity)
+
+	assert.False(t, V(2).Enabled())
+	require.NoError(t, l.SetVModule("verbosity_test=3"))
+	assert.True(t, V(3).Enabled())
+	assert.False(t, V(4).Enabled())
+	assert.True(t, l.V(3).Enabled())
+
+	require.NoError(t, l.SetVModule("other=5"))
+	assert.False(t, V(3).Enabled(), "Resolved call sites should be dropped once the overrides change")
+	assert.Equal(t, "other=5", l.VModule())
+
+	setGlobal(t, noopLogger)
+	assert.False(t, V(0).Enabled())
+}
+
+func TestVGlobalLogger(t *testing.T) {
+	l, sink := newRecordingLogger()
+	l.SetVerbosity(1)
+	setGlobal(t, l)
+
+	ol, other := newRecordingLogger()
+	ctx := WithLogger(context.Background(), ol)
+
+	V(1).Info(ctx, "Enabled")
+	require.Len(t, sink.entries, 1, "The entry should go through the logger whose verbosity was checked")
+	assert.Empty(t, other.entries)
+}
+
+func TestVDisabledAllocs(t *testing.T) {
+	setGlobal(t, NewDefaultLogger())
+
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		V(5).Infof(ctx, "Disabled %s", "value")
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestParseVModule(t *testing.T) {
+	patterns, err := parseVModule("server=2, internal/db/*=3,")
+	require.NoError(t, err)
+	assert.Equal(t, []vmodulePattern{{"server", 2}, {"internal/db/*", 3}}, patterns)
+
+	for _, spec := range []string{"server", "=2", "server=high", "[=1"} {
+		_, err := parseVModule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMatchVModule(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"server", "/src/app/server.go", true},
+		{"serv*", "/src/app/server.go", true},
+		{"server", "/src/app/server_test.go", false},
+		{"app/*", "/src/app/server.go", true},
+		{"src/app/server", "/src/app/server.go", true},
+		{"db/*", "/src/app/server.go", false},
+		{"a/b/c/d/e/f", "/b/server.go", false},
+	} {
+		assert.Equal(t, tt.want, matchVModule(tt.pattern, tt.file), "%s %s", tt.pattern, tt.file)
+	}
+}